@@ -0,0 +1,125 @@
+package poker
+
+import "fmt"
+
+// AuditAction 审计记录里的一次操作
+type AuditAction string
+
+const (
+	AuditDeal AuditAction = "deal" // 发一张牌
+	AuditBurn AuditAction = "burn" // 烧一张牌
+	AuditBack AuditAction = "back" // 回退 N 张
+	AuditSwap AuditAction = "swap" // 交换 Index 和 Swap 两个位置的牌
+)
+
+// AUDIT_BOARD 公共牌在审计记录里的接收者
+const AUDIT_BOARD = "board"
+
+// AuditEvent 一次发牌或者对牌堆的改动
+type AuditEvent struct {
+	Action    AuditAction `json:"action"`
+	Street    string      `json:"street,omitempty"`    // DealTo、SimpleDealTo、Burn 给的街
+	Recipient string      `json:"recipient,omitempty"` // DealTo、SimpleDealTo 给的接收者
+	Index     int         `json:"index"`               // 发牌、烧牌：牌的下标；交换：第一个位置
+	Card      Card        `json:"card,omitempty"`
+	Front     string      `json:"front,omitempty"` // Card 的正面，方便看
+	Swap      int         `json:"swap,omitempty"`  // 交换：第二个位置
+	N         int         `json:"n,omitempty"`     // 回退：张数
+}
+
+// DealerAudit 一副牌从洗好（或者 Restore）开始的审计记录，可以序列化成 JSON，
+// 有争议的时候用 RestoreAudit 恢复到发牌器，从 Start 开始重新发一遍
+type DealerAudit struct {
+	Seed   *int64       `json:"seed,omitempty"` // SeededRNG 创建时的种子，不是这一次洗牌前的状态
+	Fair   *FairProof   `json:"fair,omitempty"` // ShuffleFair 的证明，不含 ServerSeed，这一手结束之后另外公开
+	Start  DealerState  `json:"start"`          // 洗好之后的顺序和状态
+	Events []AuditEvent `json:"events"`
+}
+
+// Audit 当前这一副牌的审计记录
+func (d *Dealer) Audit() *DealerAudit {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
+	audit := d.audit
+	audit.Start.Burned = append([]int(nil), audit.Start.Burned...)
+	audit.Events = append([]AuditEvent(nil), audit.Events...)
+	return &audit
+}
+
+// RestoreAudit 从审计记录的 Start 开始按顺序重放每一次操作，发出来的牌要和记录的一样，
+// 之后的状态和审计记录最后一样，审计记录接着往下记；不影响随机数来源
+// 记录不合法或者对不上时返回错误，发牌器不变
+func (d *Dealer) RestoreAudit(audit *DealerAudit) error {
+	cards, err := d._checkState(&audit.Start)
+	if err != nil {
+		return err
+	}
+	replay := &Dealer{}
+	replay._restore(cards, &audit.Start)
+	for i, e := range audit.Events {
+		if err := replay._replay(e); err != nil {
+			return fmt.Errorf("audit event %d %s: %v", i, e.Action, err)
+		}
+	}
+
+	d.DealerMutex.Lock()
+	defer d.DealerMutex.Unlock()
+
+	d.cards, d.next, d.left, d.burned = replay.cards, replay.next, replay.left, replay.burned
+	d.audit = DealerAudit{
+		Seed:   audit.Seed,
+		Fair:   audit.Fair,
+		Start:  audit.Start,
+		Events: append([]AuditEvent(nil), audit.Events...),
+	}
+	d.audit.Start.Burned = append([]int(nil), audit.Start.Burned...)
+	return nil
+}
+
+func (d *Dealer) _replay(e AuditEvent) error {
+	switch e.Action {
+	case AuditDeal, AuditBurn:
+		card, index, err := d._dealOne()
+		if err != nil {
+			return err
+		}
+		if index != e.Index || card != e.Card {
+			return fmt.Errorf("dealt %v at %d, recorded %v at %d", card.Front(), index, e.Card.Front(), e.Index)
+		}
+		if e.Action == AuditBurn {
+			d.burned = append(d.burned, index)
+		}
+	case AuditBack:
+		return d._back(e.N)
+	case AuditSwap:
+		return d._swapPoker(e.Index, e.Swap)
+	default:
+		return fmt.Errorf("unknown action")
+	}
+	return nil
+}
+
+// _startAudit 从当前状态开始新的审计记录
+func (d *Dealer) _startAudit() {
+	d.audit = DealerAudit{Start: *d._state()}
+	if r, ok := d.rng.(*SeededRNG); ok {
+		seed := r.Seed()
+		d.audit.Seed = &seed
+	}
+}
+
+func (d *Dealer) _record(e AuditEvent) {
+	d.audit.Events = append(d.audit.Events, e)
+}
+
+func (d *Dealer) _recordDeal(action AuditAction, street, recipient string, card Card, index int) {
+	d._record(AuditEvent{
+		Action:    action,
+		Street:    street,
+		Recipient: recipient,
+		Index:     index,
+		Card:      card,
+		Front:     card.Front(),
+	})
+}
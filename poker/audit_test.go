@@ -0,0 +1,129 @@
+package poker
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestDealerAuditRestore(t *testing.T) {
+	d := NewSeededDealer(1, Deck, 42)
+	d.Shuffle()
+	order := d.Trace()
+	DealHoleCards(d, "hole", []string{"3", "5"}, HOLDEM_HOLE_CARDS)
+	d.SwapPoker(10, 20)
+	DealStreet(d, "flop", 3)
+	d.Back(1)
+	d.DealTo("flop", AUDIT_BOARD)
+
+	audit := d.Audit()
+	if audit.Seed == nil || *audit.Seed != 42 || audit.Start.Order != order || audit.Start.Next != 0 {
+		t.Fatal("err audit start", audit.Seed, audit.Start)
+	}
+	if len(audit.Events) != 4+1+4+1+1 {
+		t.Fatal("err events", len(audit.Events))
+	}
+	if e := audit.Events[1]; e.Action != AuditDeal || e.Recipient != "5" || e.Street != "hole" || e.Index != 1 {
+		t.Error("err hole event", e)
+	}
+	if e := audit.Events[4]; e.Action != AuditSwap || e.Index != 10 || e.Swap != 20 {
+		t.Error("err swap event", e)
+	}
+	if e := audit.Events[5]; e.Action != AuditBurn || e.Street != "flop" {
+		t.Error("err burn event", e)
+	}
+	if e := audit.Events[9]; e.Action != AuditBack || e.N != 1 {
+		t.Error("err back event", e)
+	}
+
+	data, err := json.Marshal(audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DealerAudit
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	restored := NewDealer(1, Deck)
+	if err := restored.RestoreAudit(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.State(), d.State()) || !reflect.DeepEqual(restored.Audit(), audit) {
+		t.Fatal("restored dealer differs")
+	}
+	a, _, _ := d.DealOne()
+	b, _, _ := restored.DealOne()
+	if a != b {
+		t.Error("should deal the same card after restore", a, b)
+	}
+
+	// 记录的牌对不上时返回错误，发牌器不变
+	decoded.Events[2].Card = decoded.Events[3].Card
+	before := restored.State()
+	if err := restored.RestoreAudit(&decoded); err == nil {
+		t.Fatal("expect error for tampered audit")
+	}
+	if !reflect.DeepEqual(restored.State(), before) {
+		t.Error("dealer should not change on error")
+	}
+}
+
+func TestDealerAuditFair(t *testing.T) {
+	d := NewDealer(1, Deck)
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	proof, err := d.ShuffleFair(seed, []string{"alice"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit := d.Audit()
+	if audit.Fair == nil || audit.Fair.Commitment != proof.Commitment || audit.Fair.ServerSeed != "" {
+		t.Fatal("err fair audit", audit.Fair)
+	}
+	if audit.Seed != nil || len(audit.Events) != 0 {
+		t.Error("err audit", audit)
+	}
+	if err := VerifyFairShuffle(proof, 1, Deck, audit.Start.Order); err != nil {
+		t.Error(err)
+	}
+
+	// 重新洗牌之后从头开始记
+	d.DealOne()
+	d.Shuffle()
+	if audit := d.Audit(); audit.Fair != nil || len(audit.Events) != 0 {
+		t.Error("audit should restart after shuffle", audit)
+	}
+}
+
+func TestDealerAuditConcurrentRecipients(t *testing.T) {
+	d := NewSeededDealer(2, Deck, 3)
+	d.Shuffle()
+	got := make([][]Card, 8)
+	var wg sync.WaitGroup
+	for g := range got {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 6; i++ {
+				card, _, err := d.DealTo("hole", strconv.Itoa(g))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got[g] = append(got[g], card)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// 每一条记录的接收者都要是真正拿到这张牌的人
+	next := make([]int, len(got))
+	for _, e := range d.Audit().Events {
+		g, err := strconv.Atoi(e.Recipient)
+		if err != nil || next[g] >= len(got[g]) || got[g][next[g]] != e.Card {
+			t.Fatal("err recipient", e)
+		}
+		next[g]++
+	}
+}
@@ -0,0 +1,88 @@
+package poker
+
+import "fmt"
+
+const (
+	HOLDEM_HOLE_CARDS  = 2 // 德州每个人的手牌数
+	HOLDEM_BOARD_CARDS = 5 // 德州的公共牌数
+)
+
+// IDealer 发牌的接口，Dealer 和 ScriptedDealer 都实现了它
+type IDealer interface {
+	Shuffle() error
+	DealOne() (card Card, index int, err error)
+	DealTo(street, recipient string) (card Card, index int, err error)
+	SimpleDeal(n int) ([]Card, error)
+	SimpleDealTo(street, recipient string, n int) ([]Card, error)
+	Burn(street string) (Card, error)
+	Burned() []Card
+	Audit() *DealerAudit
+	LeftPoker() int
+	Back(n int) error
+	Trace() string
+}
+
+// DealHoleCards 按赌场的发法发手牌：从第一个人（庄家左手边）开始一人一张轮流发，发 n 圈
+// recipients 是按发牌顺序每个人在审计记录里的名字，返回的手牌也按这个顺序，出错时已经发出去的牌不退回
+func DealHoleCards(d IDealer, street string, recipients []string, n int) ([][]Card, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid deal %d cards", n)
+	}
+	res := make([][]Card, len(recipients))
+	for k := 0; k < n; k++ {
+		for i, recipient := range recipients {
+			card, _, err := d.DealTo(street, recipient)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = append(res[i], card)
+		}
+	}
+	return res, nil
+}
+
+// DealStreet 按赌场的发法发一条街的公共牌：先烧一张，再发 n 张
+func DealStreet(d IDealer, street string, n int) ([]Card, error) {
+	if _, err := d.Burn(street); err != nil {
+		return nil, err
+	}
+	return d.SimpleDealTo(street, AUDIT_BOARD, n)
+}
+
+// DealLayout 一手德州里每张牌在洗好的牌堆里的位置，要和桌子实际的发牌顺序一致
+type DealLayout interface {
+	// HolePos 按发牌顺序第 seat 个人的第 k 张手牌的位置，一共发 seats 个人
+	HolePos(seats, seat, k int) int
+	// BoardPos 第 i 张公共牌的位置
+	BoardPos(seats, i int) int
+}
+
+// CasinoLayout DealHoleCards 和 DealStreet 的顺序：手牌一人一张轮流发，翻牌、转牌、河牌前各烧一张
+type CasinoLayout struct{}
+
+func (CasinoLayout) HolePos(seats, seat, k int) int {
+	return k*seats + seat
+}
+
+func (CasinoLayout) BoardPos(seats, i int) int {
+	// 烧 翻 翻 翻 烧 转 烧 河
+	pos := seats*HOLDEM_HOLE_CARDS + 1 + i
+	if i >= 3 {
+		pos++
+	}
+	if i >= 4 {
+		pos++
+	}
+	return pos
+}
+
+// SeatBySeatLayout 一个人一次用 SimpleDeal 发完手牌，然后连着发公共牌，不烧牌
+type SeatBySeatLayout struct{}
+
+func (SeatBySeatLayout) HolePos(seats, seat, k int) int {
+	return seat*HOLDEM_HOLE_CARDS + k
+}
+
+func (SeatBySeatLayout) BoardPos(seats, i int) int {
+	return seats*HOLDEM_HOLE_CARDS + i
+}
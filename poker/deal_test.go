@@ -0,0 +1,70 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCasinoDeal(t *testing.T) {
+	// 不洗牌，按 Deck 的顺序发
+	d := NewDealer(1, Deck)
+	holes, err := DealHoleCards(d, "hole", []string{"1", "2", "3"}, HOLDEM_HOLE_CARDS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := [][]Card{
+		{AceSpades, FourSpades},
+		{TwoSpades, FiveSpades},
+		{ThreeSpades, SixSpades},
+	}
+	if !reflect.DeepEqual(holes, expect) {
+		t.Fatal("err hole cards", holes)
+	}
+
+	var board []Card
+	for _, n := range []int{3, 1, 1} {
+		cards, err := DealStreet(d, "board", n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		board = append(board, cards...)
+	}
+	if !reflect.DeepEqual(board, []Card{EightSpades, NineSpades, TenSpades, QueenSpades, AceHearts}) {
+		t.Fatal("err board", board)
+	}
+	if burned := d.Burned(); !reflect.DeepEqual(burned, []Card{SevenSpades, JackSpades, KingSpades}) {
+		t.Fatal("err burned", burned)
+	}
+
+	// 退回去的烧牌不再算
+	d.Back(2)
+	if burned := d.Burned(); len(burned) != 2 {
+		t.Error("err burned after back", burned)
+	}
+	// 洗牌之后清空
+	d.Shuffle()
+	if burned := d.Burned(); len(burned) != 0 {
+		t.Error("err burned after shuffle", burned)
+	}
+}
+
+func TestBurnedState(t *testing.T) {
+	d := NewSeededDealer(1, Deck, 5)
+	d.Shuffle()
+	DealHoleCards(d, "hole", []string{"1", "2"}, HOLDEM_HOLE_CARDS)
+	DealStreet(d, "flop", 3)
+
+	restored := NewDealer(1, Deck)
+	if err := restored.Restore(d.State()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Burned(), d.Burned()) || len(d.Burned()) != 1 {
+		t.Fatal("err burned", restored.Burned(), d.Burned())
+	}
+
+	state := d.State()
+	state.Burned = []int{state.Next}
+	if err := restored.Restore(state); err == nil {
+		t.Error("expect error for burned card not dealt")
+	}
+}
@@ -2,12 +2,10 @@ package poker
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"strconv"
 	"sync"
-
-	"errors"
-	"math/rand"
-	"time"
 )
 
 const (
@@ -17,38 +15,66 @@ const (
 // 发牌器
 type Dealer struct {
 	DealerMutex sync.RWMutex
-	rand        *rand.Rand
+	rng         RNG
+	raw         []Card // 初始的顺序，ShuffleFair 从这里开始洗
 	cards       []Card
 	left        int
 	next        int
+	burned      []int // 这一副烧掉的牌的下标
+
+	audit DealerAudit
 }
 
+// NewDealer 创建发牌器，用 crypto/rand 洗牌
 func NewDealer(deckNum int, rawDeck []Card) *Dealer {
+	return NewDealerWithRNG(deckNum, rawDeck, NewCryptoRNG())
+}
+
+// NewDealerWithRNG 用指定的随机数来源创建发牌器
+func NewDealerWithRNG(deckNum int, rawDeck []Card, rng RNG) *Dealer {
 	Dealer := Dealer{
-		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:   rng,
 		cards: make([]Card, deckNum*len(rawDeck)),
 	}
 	for i := 0; i < deckNum; i++ {
 		copy(Dealer.cards[i*len(rawDeck):], rawDeck)
 	}
+	Dealer.raw = append([]Card(nil), Dealer.cards...)
+	Dealer.left = len(Dealer.cards)
+	Dealer._startAudit()
 
 	return &Dealer
 }
 
-func (d *Dealer) Shuffle() {
+// SetRNG 更换洗牌的随机数来源
+func (d *Dealer) SetRNG(rng RNG) {
 	d.DealerMutex.Lock()
-	d.rand.Seed(time.Now().UnixNano())
+	defer d.DealerMutex.Unlock()
 
-	le := len(d.cards)
+	d.rng = rng
+}
 
-	for i := 0; i < le; i++ {
-		j := d.rand.Intn(le-i) + i
-		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+// Shuffle 洗牌，随机数来源出错时牌的顺序不变
+func (d *Dealer) Shuffle() error {
+	d.DealerMutex.Lock()
+	defer d.DealerMutex.Unlock()
+
+	cards := append([]Card(nil), d.cards...)
+	if err := _shuffle(cards, d.rng); err != nil {
+		return err
 	}
+	copy(d.cards, cards)
 
+	d._reset()
+	return nil
+}
+
+// _reset 洗完牌从头开始发
+func (d *Dealer) _reset() {
 	d.next = 0
 	d.left = len(d.cards)
-	d.DealerMutex.Unlock()
+	d.burned = nil
+	d._startAudit()
 }
 
 func (d *Dealer) ReserveFindIf(pred func(card Card) bool) (index int) {
@@ -79,11 +105,24 @@ func (d *Dealer) FindIf(pred func(card Card) bool) (index int) {
 	return CardNPos
 }
 
-func (d *Dealer) SwapPoker(i, j int) {
+// SwapPoker 交换两个位置的牌，下标越界时返回错误
+func (d *Dealer) SwapPoker(i, j int) error {
 	d.DealerMutex.Lock()
 	defer d.DealerMutex.Unlock()
 
+	if err := d._swapPoker(i, j); err != nil {
+		return err
+	}
+	d._record(AuditEvent{Action: AuditSwap, Index: i, Swap: j})
+	return nil
+}
+
+func (d *Dealer) _swapPoker(i, j int) error {
+	if i < 0 || i >= d._totalPoker() || j < 0 || j >= d._totalPoker() {
+		return fmt.Errorf("swap %d %d out of range [0, %d)", i, j, d._totalPoker())
+	}
 	d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	return nil
 }
 
 func (d *Dealer) TotalPoker() int {
@@ -108,12 +147,39 @@ func (d *Dealer) PokerAt(index int) (Card, error) {
 }
 
 func (d *Dealer) LeftPoker() int {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
 	return d.left
 }
 
+// Dealt 已经发出去的牌（包括烧掉的），按发牌顺序，返回的是副本
+func (d *Dealer) Dealt() []Card {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
+	return append([]Card(nil), d.cards[:d.next]...)
+}
+
+// Remaining 还没发的牌，按之后发牌的顺序，返回的是副本
+func (d *Dealer) Remaining() []Card {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
+	return append([]Card(nil), d.cards[d.next:]...)
+}
+
 func (d *Dealer) DealOne() (card Card, index int, err error) {
+	return d.DealTo("", "")
+}
+
+// DealTo 发一张牌给 recipient，街和接收者和这张牌一起记到审计记录里
+func (d *Dealer) DealTo(street, recipient string) (card Card, index int, err error) {
 	d.DealerMutex.Lock()
 	card, index, err = d._dealOne()
+	if err == nil {
+		d._recordDeal(AuditDeal, street, recipient, card, index)
+	}
 	d.DealerMutex.Unlock()
 	return
 }
@@ -149,33 +215,86 @@ func (d *Dealer) FirstCard() Card {
 	return d.cards[0]
 }
 
+// SimpleDeal 连着发 n 张，n 为负数或者剩下的牌不够时返回错误，一张都不发
 func (d *Dealer) SimpleDeal(n int) ([]Card, error) {
+	return d.SimpleDealTo("", "", n)
+}
+
+// SimpleDealTo 连着发 n 张给 recipient，同 SimpleDeal，街和接收者记到审计记录里
+func (d *Dealer) SimpleDealTo(street, recipient string, n int) ([]Card, error) {
 	d.DealerMutex.Lock()
 	defer d.DealerMutex.Unlock()
 
-	var res []Card
-	for n != 0 {
-		card, _, err := d._dealOne()
-		if err == nil {
-			res = append(res, card)
-			n--
-		} else {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid deal %d cards", n)
+	}
+	if n > d._totalPoker()-d.next {
+		return nil, errors.New("poker use out")
+	}
+	res := make([]Card, 0, n)
+	for ; n > 0; n-- {
+		card, index, err := d._dealOne()
+		if err != nil {
 			return res, err
 		}
+		d._recordDeal(AuditDeal, street, recipient, card, index)
+		res = append(res, card)
 	}
 	return res, nil
 }
 
-// 回退
-func (d *Dealer) Back(n int) {
+// Back 回退 n 张，n 为负数或者超过已经发出去的张数时返回错误
+func (d *Dealer) Back(n int) error {
 	d.DealerMutex.Lock()
 	defer d.DealerMutex.Unlock()
 
+	if err := d._back(n); err != nil {
+		return err
+	}
+	d._record(AuditEvent{Action: AuditBack, N: n})
+	return nil
+}
+
+func (d *Dealer) _back(n int) error {
+	if n < 0 || n > d.next {
+		return fmt.Errorf("back %d with %d dealt", n, d.next)
+	}
 	for n > 0 {
 		d.left++
 		d.next--
 		n--
 	}
+	// 退回去的牌不再算烧掉
+	for len(d.burned) > 0 && d.burned[len(d.burned)-1] >= d.next {
+		d.burned = d.burned[:len(d.burned)-1]
+	}
+	return nil
+}
+
+// Burn 在 street 之前烧一张牌，烧掉的牌单独记录，不发给任何人
+func (d *Dealer) Burn(street string) (Card, error) {
+	d.DealerMutex.Lock()
+	defer d.DealerMutex.Unlock()
+
+	card, index, err := d._dealOne()
+	if err != nil {
+		return card, err
+	}
+	d.burned = append(d.burned, index)
+	d._recordDeal(AuditBurn, street, "", card, index)
+	return card, nil
+}
+
+// Burned 这一副已经烧掉的牌，按烧的顺序
+func (d *Dealer) Burned() []Card {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
+	res := make([]Card, len(d.burned))
+	for i, index := range d.burned {
+		res[i] = d.cards[index]
+	}
+	return res
 }
 
 func (d *Dealer) Trace() string {
@@ -186,8 +305,12 @@ func (d *Dealer) Trace() string {
 }
 
 func (d *Dealer) _trace() string {
+	return _traceCards(d.cards)
+}
+
+func _traceCards(cards []Card) string {
 	var buffer bytes.Buffer
-	for _, card := range d.cards {
+	for _, card := range cards {
 		buffer.WriteString(strconv.Itoa(int(card)))
 		buffer.WriteString(";")
 	}
@@ -0,0 +1,133 @@
+package poker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DealerState 发牌器的完整状态，可以序列化成 JSON 保存，之后用 Restore 原样恢复
+type DealerState struct {
+	Order string `json:"order"` // 牌的顺序，和 Dealer.Trace 的格式一样
+	Next  int    `json:"next"`  // 下一张要发的牌的下标
+	Left  int    `json:"left"`  // 还剩多少张，等于牌的张数减去 Next
+
+	Burned []int `json:"burned,omitempty"` // 烧掉的牌的下标
+}
+
+// NewSeededDealer 用固定的种子创建发牌器，同样的种子洗出同样的牌，
+// 之后 DealOne、SimpleDeal 发出的牌也完全一样，用来回放和测试
+func NewSeededDealer(deckNum int, rawDeck []Card, seed int64) *Dealer {
+	return NewDealerWithRNG(deckNum, rawDeck, NewSeededRNG(seed))
+}
+
+// Seed 用 NewSeededDealer 或者 SeededRNG 创建的发牌器的种子
+func (d *Dealer) Seed() (seed int64, ok bool) {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
+	if r, ok := d.rng.(*SeededRNG); ok {
+		return r.Seed(), true
+	}
+	return 0, false
+}
+
+// State 导出牌的顺序和发到了哪里
+func (d *Dealer) State() *DealerState {
+	d.DealerMutex.RLock()
+	defer d.DealerMutex.RUnlock()
+
+	return d._state()
+}
+
+func (d *Dealer) _state() *DealerState {
+	return &DealerState{
+		Order: d._trace(),
+		Next:  d.next,
+		Left:  d.left,
+
+		Burned: append([]int(nil), d.burned...),
+	}
+}
+
+// Restore 恢复 State 导出的状态，之后发的牌和导出时一样；不影响随机数来源
+// 状态不合法（牌和发牌器的牌不一样、Next 和 Left 对不上等）时返回错误，发牌器不变；审计记录从恢复的状态重新开始
+func (d *Dealer) Restore(state *DealerState) error {
+	cards, err := d._checkState(state)
+	if err != nil {
+		return err
+	}
+
+	d.DealerMutex.Lock()
+	defer d.DealerMutex.Unlock()
+
+	d._restore(cards, state)
+	d._startAudit()
+	return nil
+}
+
+func (d *Dealer) _restore(cards []Card, state *DealerState) {
+	d.cards = cards
+	d.next = state.Next
+	d.left = state.Left
+	d.burned = append([]int(nil), state.Burned...)
+}
+
+// _checkState 检查状态，返回牌的顺序：牌要和创建时的牌一样（顺序可以不同），Left 要等于没发的张数
+func (d *Dealer) _checkState(state *DealerState) ([]Card, error) {
+	cards, err := ParseTrace(state.Order)
+	if err != nil {
+		return nil, err
+	}
+	if err := _sameCards(cards, d.raw); err != nil {
+		return nil, err
+	}
+	if state.Next < 0 || state.Next > len(cards) {
+		return nil, fmt.Errorf("next %d out of range [0, %d]", state.Next, len(cards))
+	}
+	if state.Left != len(cards)-state.Next {
+		return nil, fmt.Errorf("left %d does not match next %d of %d cards", state.Left, state.Next, len(cards))
+	}
+	for i, index := range state.Burned {
+		if index < 0 || index >= state.Next || (i > 0 && index <= state.Burned[i-1]) {
+			return nil, fmt.Errorf("invalid burned card index %d", index)
+		}
+	}
+	return cards, nil
+}
+
+// _sameCards cards 和 raw 是不是同样的一堆牌
+func _sameCards(cards, raw []Card) error {
+	if len(cards) != len(raw) {
+		return fmt.Errorf("%d cards, deck has %d", len(cards), len(raw))
+	}
+	count := make(map[Card]int, len(raw))
+	for _, c := range raw {
+		count[c]++
+	}
+	for _, c := range cards {
+		if count[c] == 0 {
+			return fmt.Errorf("card %d is not in the deck or appears too many times", c)
+		}
+		count[c]--
+	}
+	return nil
+}
+
+// ParseTrace 解析 Dealer.Trace 格式的牌："20;36;..."，最后的分号可以没有
+func ParseTrace(trace string) ([]Card, error) {
+	trace = strings.TrimSuffix(trace, ";")
+	if trace == "" {
+		return nil, nil
+	}
+	strs := strings.Split(trace, ";")
+	cards := make([]Card, len(strs))
+	for i, s := range strs {
+		v, err := strconv.ParseUint(strings.TrimSpace(s), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid card %q at %d", s, i)
+		}
+		cards[i] = Card(v)
+	}
+	return cards, nil
+}
@@ -0,0 +1,200 @@
+package poker
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// dealHand 按一手牌的顺序发：4 个人的手牌，翻牌，转牌，河牌
+func dealHand(t *testing.T, d *Dealer) []Card {
+	t.Helper()
+	var res []Card
+	for i := 0; i < 8; i++ {
+		c, _, err := d.DealOne()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, c)
+	}
+	for _, n := range []int{3, 1, 1} {
+		cards, err := d.SimpleDeal(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, cards...)
+	}
+	return res
+}
+
+func TestSeededDealerReplay(t *testing.T) {
+	a, b := NewSeededDealer(1, Deck, 20200101), NewSeededDealer(1, Deck, 20200101)
+	for hand := 0; hand < 3; hand++ {
+		a.Shuffle()
+		b.Shuffle()
+		if !reflect.DeepEqual(dealHand(t, a), dealHand(t, b)) {
+			t.Fatal("same seed should deal the same cards, hand", hand)
+		}
+	}
+	if seed, ok := a.Seed(); !ok || seed != 20200101 {
+		t.Error("err seed", seed, ok)
+	}
+	if _, ok := NewDealer(1, Deck).Seed(); ok {
+		t.Error("crypto dealer has no seed")
+	}
+
+	c := NewSeededDealer(1, Deck, 20200102)
+	c.Shuffle()
+	a.Shuffle()
+	if reflect.DeepEqual(dealHand(t, a), dealHand(t, c)) {
+		t.Error("different seed should deal different cards")
+	}
+}
+
+func TestDealerStateRoundTrip(t *testing.T) {
+	d := NewSeededDealer(1, Deck, 5)
+	d.Shuffle()
+	d.SimpleDeal(5)
+
+	data, err := json.Marshal(d.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &DealerState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Next != 5 || state.Left != len(Deck)-5 {
+		t.Error("err state", state)
+	}
+
+	restored := NewDealer(1, Deck)
+	if err := restored.Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Trace() != d.Trace() || restored.LeftPoker() != d.LeftPoker() {
+		t.Error("err restored dealer")
+	}
+	expect, _ := d.SimpleDeal(10)
+	got, _ := restored.SimpleDeal(10)
+	if !reflect.DeepEqual(expect, got) {
+		t.Error("restored dealer should deal the same cards", expect, got)
+	}
+
+	bad := []*DealerState{
+		{Order: "20;36;x;", Next: 0, Left: 3},
+		{Order: "20;36;", Next: 3, Left: 0},
+		{Order: "20;36;", Next: 0, Left: -1},
+		{Order: "20;36;", Next: 0, Left: 1},
+		{Order: d.Trace(), Next: 10, Left: 40},
+		{Order: "20;20;20;", Next: 0, Left: 3},
+		{Order: strings.Repeat("20;", len(Deck)), Next: 0, Left: len(Deck)},
+	}
+	trace := restored.Trace()
+	for _, s := range bad {
+		if err := restored.Restore(s); err == nil || restored.Trace() != trace {
+			t.Error("invalid state should fail", s)
+		}
+	}
+}
+
+func TestDealerBounds(t *testing.T) {
+	d := NewSeededDealer(1, Deck, 1)
+	if d.LeftPoker() != len(Deck) {
+		t.Error("err left before shuffle", d.LeftPoker())
+	}
+	d.Shuffle()
+	if err := d.Back(1); err == nil {
+		t.Error("expect error for back before dealing")
+	}
+	if err := d.SwapPoker(0, len(Deck)); err == nil {
+		t.Error("expect error for swap out of range")
+	}
+	if err := d.SwapPoker(-1, 0); err == nil {
+		t.Error("expect error for negative swap")
+	}
+	if cards, err := d.SimpleDeal(-1); err == nil || len(cards) != 0 {
+		t.Error("expect error for negative deal", cards)
+	}
+	if cards, err := d.SimpleDeal(0); err != nil || len(cards) != 0 {
+		t.Error("deal 0 should be empty", cards, err)
+	}
+
+	cards, err := d.SimpleDeal(50)
+	if err != nil || len(cards) != 50 {
+		t.Fatal(cards, err)
+	}
+	// 剩下的不够时一张都不发
+	if _, err := d.SimpleDeal(3); err == nil || d.LeftPoker() != 2 {
+		t.Error("should not deal when not enough cards", d.LeftPoker())
+	}
+	if !reflect.DeepEqual(d.Dealt(), cards) || len(d.Remaining()) != 2 {
+		t.Error("err views", d.Dealt(), d.Remaining())
+	}
+	if err := d.Back(51); err == nil || d.LeftPoker() != 2 {
+		t.Error("expect error for back too many", d.LeftPoker())
+	}
+	if err := d.Back(50); err != nil || d.LeftPoker() != len(Deck) || len(d.Dealt()) != 0 {
+		t.Error("err back", err, d.LeftPoker())
+	}
+
+	// 返回的是副本
+	d.Remaining()[0] = 0
+	if c, _ := d.PokerAt(0); c == 0 {
+		t.Error("remaining should be a copy")
+	}
+}
+
+// go test -race 跑这个，多个 goroutine 同时调用发牌器的所有方法
+func TestDealerConcurrent(t *testing.T) {
+	d := NewSeededDealer(2, Deck, 9)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				switch (g + i) % 8 {
+				case 0:
+					d.Shuffle()
+				case 1:
+					d.SimpleDeal(3)
+					d.Back(2)
+				case 2:
+					d.DealOne()
+					d.Burn("turn")
+				case 3:
+					d.SwapPoker(i%d.TotalPoker(), (i*7)%d.TotalPoker())
+					d.LeftPoker()
+				case 4:
+					d.Dealt()
+					d.Remaining()
+					d.Burned()
+				case 5:
+					d.Trace()
+					d.FindIf(func(c Card) bool { return c == AceSpades })
+					d.ReserveFindIf(func(c Card) bool { return c == AceSpades })
+				case 6:
+					d.Restore(d.State())
+					d.DealTo("flop", AUDIT_BOARD)
+				case 7:
+					d.RestoreAudit(d.Audit())
+					d.FirstCard()
+				}
+				// 不管怎么并发，状态都要一致
+				if state := d.State(); state.Next+state.Left != d.TotalPoker() {
+					t.Error("err dealer state", state.Next, state.Left)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	state := d.State()
+	if state.Next+state.Left != d.TotalPoker() {
+		t.Error("err next and left", state.Next, state.Left)
+	}
+}
@@ -0,0 +1,154 @@
+package poker
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+/* 可验证公平的洗牌（commit-reveal）
+---------------------------------------------------------------
+1、开局前服务器生成 ServerSeed，只公开它的 sha256（Commitment）
+2、玩家提交各自的 ClientSeed
+3、从初始顺序的牌开始按 Dealer.Shuffle 一样的方法洗牌，随机数来自
+   HMAC-SHA256(key=ServerSeed, ClientSeed... | Nonce | 计数器) 组成的字节流，用 Intn 取区间里的整数
+4、这一手结束后公开 ServerSeed，任何人都可以检查它的 sha256 等于开局前的 Commitment，
+   再算出洗牌之后的顺序，和 Dealer.Trace 对比
+*/
+
+// SERVER_SEED_SIZE 服务器种子的字节数
+const SERVER_SEED_SIZE = 32
+
+// FairProof 一次洗牌的证明，ServerSeed 在这一手结束之前不能公开
+type FairProof struct {
+	Commitment  string   `json:"commitment"`  // ServerSeed 的 sha256，十六进制
+	ServerSeed  string   `json:"server_seed"` // 十六进制
+	ClientSeeds []string `json:"client_seeds"`
+	Nonce       uint64   `json:"nonce"` // 同一个 ServerSeed 用于多手牌时区分每一手
+}
+
+// NewServerSeed 用 crypto/rand 生成服务器种子
+func NewServerSeed() ([]byte, error) {
+	seed := make([]byte, SERVER_SEED_SIZE)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// CommitSeed 服务器种子的承诺，开局前公开
+func CommitSeed(serverSeed []byte) string {
+	sum := sha256.Sum256(serverSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewFairProof 生成这一手的证明
+func NewFairProof(serverSeed []byte, clientSeeds []string, nonce uint64) *FairProof {
+	return &FairProof{
+		Commitment:  CommitSeed(serverSeed),
+		ServerSeed:  hex.EncodeToString(serverSeed),
+		ClientSeeds: append([]string(nil), clientSeeds...),
+		Nonce:       nonce,
+	}
+}
+
+// Hidden 开局前可以公开的部分，不含 ServerSeed
+func (p *FairProof) Hidden() *FairProof {
+	res := *p
+	res.ServerSeed = ""
+	res.ClientSeeds = append([]string(nil), p.ClientSeeds...)
+	return &res
+}
+
+// hmacStream HMAC-SHA256 生成的随机字节流
+type hmacStream struct {
+	msg     []byte // HMAC 的消息前缀：ClientSeed... | Nonce
+	key     []byte
+	counter uint64
+	block   []byte
+	pos     int
+}
+
+func newHmacStream(serverSeed []byte, clientSeeds []string, nonce uint64) *hmacStream {
+	var msg []byte
+	var buf [binary.MaxVarintLen64]byte
+	// 每个 ClientSeed 前面加长度，避免 "ab"+"c" 和 "a"+"bc" 得到一样的结果
+	for _, s := range clientSeeds {
+		n := binary.PutUvarint(buf[:], uint64(len(s)))
+		msg = append(msg, buf[:n]...)
+		msg = append(msg, s...)
+	}
+	binary.BigEndian.PutUint64(buf[:8], nonce)
+	msg = append(msg, buf[:8]...)
+	return &hmacStream{msg: msg, key: serverSeed}
+}
+
+func (s *hmacStream) Uint32() (uint32, error) {
+	if s.pos+4 > len(s.block) {
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], s.counter)
+		s.counter++
+		h := hmac.New(sha256.New, s.key)
+		h.Write(s.msg)
+		h.Write(counter[:])
+		s.block = h.Sum(s.block[:0])
+		s.pos = 0
+	}
+	v := binary.BigEndian.Uint32(s.block[s.pos:])
+	s.pos += 4
+	return v, nil
+}
+
+// _fairShuffle 按 Dealer.Shuffle 的方法用 HMAC 字节流洗牌
+func _fairShuffle(cards []Card, serverSeed []byte, clientSeeds []string, nonce uint64) {
+	// HMAC 字节流不会出错
+	_shuffle(cards, newHmacStream(serverSeed, clientSeeds, nonce))
+}
+
+// FairDeck 从 deckNum 副 rawDeck 的初始顺序开始洗牌，得到的顺序和 Dealer.ShuffleFair 一样
+func FairDeck(deckNum int, rawDeck []Card, serverSeed []byte, clientSeeds []string, nonce uint64) []Card {
+	cards := make([]Card, deckNum*len(rawDeck))
+	for i := 0; i < deckNum; i++ {
+		copy(cards[i*len(rawDeck):], rawDeck)
+	}
+	_fairShuffle(cards, serverSeed, clientSeeds, nonce)
+	return cards
+}
+
+// ShuffleFair 可验证公平的洗牌，从 NewDealer 时的初始顺序开始洗，返回这一手的证明
+// 证明里的 ServerSeed 要等这一手结束之后才能公开
+func (d *Dealer) ShuffleFair(serverSeed []byte, clientSeeds []string, nonce uint64) (*FairProof, error) {
+	if len(serverSeed) == 0 {
+		return nil, errors.New("empty server seed")
+	}
+	d.DealerMutex.Lock()
+	defer d.DealerMutex.Unlock()
+
+	copy(d.cards, d.raw)
+	_fairShuffle(d.cards, serverSeed, clientSeeds, nonce)
+	d._reset()
+	proof := NewFairProof(serverSeed, clientSeeds, nonce)
+	d.audit.Fair = proof.Hidden()
+	return proof, nil
+}
+
+// VerifyFairShuffle 验证公开之后的证明：ServerSeed 和开局前的承诺一致，
+// 并且重新洗出来的顺序和 trace（Dealer.Trace 的结果）一致
+func VerifyFairShuffle(proof *FairProof, deckNum int, rawDeck []Card, trace string) error {
+	seed, err := hex.DecodeString(proof.ServerSeed)
+	if err != nil {
+		return fmt.Errorf("invalid server seed: %v", err)
+	}
+	if CommitSeed(seed) != proof.Commitment {
+		return errors.New("server seed does not match the commitment")
+	}
+	cards := FairDeck(deckNum, rawDeck, seed, proof.ClientSeeds, proof.Nonce)
+	if got := _traceCards(cards); got != trace {
+		return fmt.Errorf("deck order mismatch: expect %s, got %s", trace, got)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package poker
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestShuffleFair(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, SERVER_SEED_SIZE)
+	clientSeeds := []string{"alice", "bob"}
+
+	d := NewDealer(1, Deck)
+	proof, err := d.ShuffleFair(seed, clientSeeds, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Hidden().ServerSeed != "" || proof.ServerSeed == "" {
+		t.Error("err hidden proof")
+	}
+	if err := VerifyFairShuffle(proof, 1, Deck, d.Trace()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 洗出来的还是一副完整的牌
+	cards := FairDeck(1, Deck, seed, clientSeeds, 3)
+	sorted := append([]Card(nil), cards...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	raw := append([]Card(nil), Deck...)
+	sort.Slice(raw, func(i, j int) bool { return raw[i] < raw[j] })
+	if !bytes.Equal(cardBytes(sorted), cardBytes(raw)) {
+		t.Error("shuffled deck is not a permutation")
+	}
+
+	// 之前洗过牌也不影响结果
+	d.Shuffle()
+	d.DealOne()
+	if _, err := d.ShuffleFair(seed, clientSeeds, 3); err != nil || d.Trace() != _traceCards(cards) || d.LeftPoker() != len(Deck) {
+		t.Error("fair shuffle should start from the initial order")
+	}
+
+	for name, other := range map[string][]Card{
+		"nonce":         FairDeck(1, Deck, seed, clientSeeds, 4),
+		"client seed":   FairDeck(1, Deck, seed, []string{"alice", "bobby"}, 3),
+		"seed boundary": FairDeck(1, Deck, seed, []string{"aliceb", "ob"}, 3),
+	} {
+		if bytes.Equal(cardBytes(other), cardBytes(cards)) {
+			t.Error("different", name, "should give a different deck")
+		}
+	}
+}
+
+func TestVerifyFairShuffleRejects(t *testing.T) {
+	seed := bytes.Repeat([]byte{1}, SERVER_SEED_SIZE)
+	d := NewDealer(2, Deck)
+	proof, _ := d.ShuffleFair(seed, []string{"carol"}, 0)
+	trace := d.Trace()
+
+	if err := VerifyFairShuffle(proof, 1, Deck, trace); err == nil {
+		t.Error("wrong deck number should fail")
+	}
+
+	tampered := *proof
+	tampered.ServerSeed = CommitSeed([]byte("another seed"))
+	if err := VerifyFairShuffle(&tampered, 2, Deck, trace); err == nil {
+		t.Error("seed not matching the commitment should fail")
+	}
+
+	d.SwapPoker(0, 1)
+	err := VerifyFairShuffle(proof, 2, Deck, d.Trace())
+	if err == nil {
+		t.Fatal("tampered deck should fail")
+	}
+	if expect := "expect " + d.Trace() + ", got " + trace; !strings.HasSuffix(err.Error(), expect) {
+		t.Error("err message", err)
+	}
+}
+
+func cardBytes(cards []Card) []byte {
+	res := make([]byte, len(cards))
+	for i, c := range cards {
+		res[i] = byte(c)
+	}
+	return res
+}
+
+// 其它语言实现验证程序时可以用这个结果对照
+func TestFairDeckVector(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, SERVER_SEED_SIZE)
+	expect := "33;164;67;195;68;52;212;97;34;65;145;113;131;82;84;179;194;209;178;132;98;51;35;211;130;163;" +
+		"19;83;99;210;36;20;18;161;115;177;129;180;114;147;148;196;66;81;50;17;49;100;116;162;193;146;"
+	if got := _traceCards(FairDeck(1, Deck, seed, []string{"alice", "bob"}, 3)); got != expect {
+		t.Error("err fair deck", got)
+	}
+}
@@ -0,0 +1,153 @@
+package poker
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// RNG 洗牌用的随机数来源
+type RNG interface {
+	// Uint32 均匀分布的 32 位随机数
+	Uint32() (uint32, error)
+}
+
+// ErrReplayExhausted 回放的随机数用完了
+var ErrReplayExhausted = errors.New("replay rng exhausted")
+
+// Intn 用 rng 取 [0, n) 的均匀整数
+// 2^32 不是 n 的整数倍时，最后不完整的那一段直接丢掉重取（拒绝采样），没有取模偏差
+func Intn(rng RNG, n int) (int, error) {
+	if n <= 0 {
+		return 0, errors.New("invalid argument to Intn")
+	}
+	limit := (1 << 32) - (1<<32)%uint64(n)
+	for {
+		v, err := rng.Uint32()
+		if err != nil {
+			return 0, err
+		}
+		if uint64(v) < limit {
+			return int(uint64(v) % uint64(n)), nil
+		}
+	}
+}
+
+// _shuffle Fisher-Yates 洗牌，第 i 张和 [i, le) 里均匀选出的一张交换
+func _shuffle(cards []Card, rng RNG) error {
+	le := len(cards)
+	for i := 0; i < le; i++ {
+		j, err := Intn(rng, le-i)
+		if err != nil {
+			return err
+		}
+		j += i
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+	return nil
+}
+
+// CryptoRNG 操作系统的密码学安全随机数（crypto/rand），NewDealer 默认用这个
+type CryptoRNG struct {
+	mu  sync.Mutex
+	buf [256]byte
+	pos int
+}
+
+func NewCryptoRNG() *CryptoRNG {
+	return &CryptoRNG{pos: 256}
+}
+
+func (r *CryptoRNG) Uint32() (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pos+4 > len(r.buf) {
+		if _, err := crand.Read(r.buf[:]); err != nil {
+			return 0, err
+		}
+		r.pos = 0
+	}
+	v := binary.BigEndian.Uint32(r.buf[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// SeededRNG 固定种子的伪随机数，同样的种子得到同样的序列，只用于测试和复现
+type SeededRNG struct {
+	mu   sync.Mutex
+	seed int64
+	rand *rand.Rand
+}
+
+func NewSeededRNG(seed int64) *SeededRNG {
+	return &SeededRNG{seed: seed, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Seed 创建时的种子
+func (r *SeededRNG) Seed() int64 {
+	return r.seed
+}
+
+func (r *SeededRNG) Uint32() (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Uint32(), nil
+}
+
+// RecordingRNG 记录另一个 RNG 给出的每一个数，之后可以用 ReplayRNG 原样回放
+type RecordingRNG struct {
+	mu     sync.Mutex
+	src    RNG
+	values []uint32
+}
+
+func NewRecordingRNG(src RNG) *RecordingRNG {
+	return &RecordingRNG{src: src}
+}
+
+func (r *RecordingRNG) Uint32() (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, err := r.src.Uint32()
+	if err == nil {
+		r.values = append(r.values, v)
+	}
+	return v, err
+}
+
+// Values 到现在为止记录下来的数
+func (r *RecordingRNG) Values() []uint32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]uint32(nil), r.values...)
+}
+
+// ReplayRNG 按顺序回放记录下来的数，用完之后返回 ErrReplayExhausted
+type ReplayRNG struct {
+	mu     sync.Mutex
+	values []uint32
+	pos    int
+}
+
+func NewReplayRNG(values []uint32) *ReplayRNG {
+	return &ReplayRNG{values: append([]uint32(nil), values...)}
+}
+
+func (r *ReplayRNG) Uint32() (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pos >= len(r.values) {
+		return 0, ErrReplayExhausted
+	}
+	r.pos++
+	return r.values[r.pos-1], nil
+}
+
+// Remaining 还没回放的数的个数
+func (r *ReplayRNG) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.values) - r.pos
+}
@@ -0,0 +1,114 @@
+package poker
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// chiSquare 观察到的次数和期望次数的卡方统计量
+func chiSquare(counts []int, expect float64) float64 {
+	sum := 0.0
+	for _, c := range counts {
+		d := float64(c) - expect
+		sum += d * d / expect
+	}
+	return sum
+}
+
+// chiSquareLimit 自由度 df 的卡方分布 99.99% 分位数的近似值（Wilson-Hilferty），crypto 的测试偶尔误报的概率很小
+func chiSquareLimit(df int) float64 {
+	const z = 3.72
+	k := float64(df)
+	x := 1 - 2/(9*k) + z*math.Sqrt(2/(9*k))
+	return k * x * x * x
+}
+
+func TestIntnRejectsBiasedValues(t *testing.T) {
+	// 2^32 % 3 == 1，最大的那个数要丢掉
+	rng := NewReplayRNG([]uint32{math.MaxUint32, 5})
+	v, err := Intn(rng, 3)
+	if err != nil || v != 2 || rng.Remaining() != 0 {
+		t.Error("err intn", v, err, rng.Remaining())
+	}
+	if _, err := Intn(rng, 3); !errors.Is(err, ErrReplayExhausted) {
+		t.Error("err", err)
+	}
+	if _, err := Intn(rng, 0); err == nil {
+		t.Error("n = 0 should fail")
+	}
+}
+
+func TestShuffleUniformPositions(t *testing.T) {
+	const rounds = 20000
+	for name, rng := range map[string]RNG{
+		"seeded": NewSeededRNG(1),
+		"crypto": NewCryptoRNG(),
+	} {
+		d := NewDealerWithRNG(1, Deck, rng)
+		n := len(Deck)
+		index := make(map[Card]int, n)
+		for i, c := range Deck {
+			index[c] = i
+		}
+		// counts[牌*n+位置] 每张牌出现在每个位置的次数
+		counts := make([]int, n*n)
+		for r := 0; r < rounds; r++ {
+			if err := d.Shuffle(); err != nil {
+				t.Fatal(err)
+			}
+			for pos := 0; pos < n; pos++ {
+				c, _ := d.PokerAt(pos)
+				counts[index[c]*n+pos]++
+			}
+		}
+		stat := chiSquare(counts, float64(rounds)/float64(n))
+		if limit := chiSquareLimit((n - 1) * (n - 1)); stat > limit {
+			t.Error(name, "positions not uniform", stat, limit)
+		}
+	}
+}
+
+func TestShuffleUniformPermutations(t *testing.T) {
+	const rounds = 48000
+	raw := []Card{AceSpades, TwoSpades, ThreeSpades, FourSpades}
+	d := NewDealerWithRNG(1, raw, NewSeededRNG(2))
+	counts := make(map[string]int)
+	for r := 0; r < rounds; r++ {
+		d.Shuffle()
+		counts[d.Trace()]++
+	}
+	if len(counts) != 24 {
+		t.Fatal("err permutations", len(counts))
+	}
+	list := make([]int, 0, len(counts))
+	for _, c := range counts {
+		list = append(list, c)
+	}
+	if stat, limit := chiSquare(list, rounds/24), chiSquareLimit(23); stat > limit {
+		t.Error("permutations not uniform", stat, limit)
+	}
+}
+
+func TestRecordReplayRNG(t *testing.T) {
+	rec := NewRecordingRNG(NewCryptoRNG())
+	d := NewDealerWithRNG(2, Deck, rec)
+	d.Shuffle()
+	trace := d.Trace()
+
+	replay := NewDealerWithRNG(2, Deck, NewReplayRNG(rec.Values()))
+	if err := replay.Shuffle(); err != nil || replay.Trace() != trace {
+		t.Error("replay should give the same deck", err)
+	}
+	// 用完之后洗牌失败，牌的顺序不变
+	if err := replay.Shuffle(); !errors.Is(err, ErrReplayExhausted) || replay.Trace() != trace {
+		t.Error("exhausted replay should fail without touching the deck", err)
+	}
+
+	a, b := NewDealerWithRNG(1, Deck, NewSeededRNG(7)), NewDealerWithRNG(1, Deck, NewSeededRNG(7))
+	a.Shuffle()
+	b.Shuffle()
+	if a.Trace() != b.Trace() {
+		t.Error("same seed should give the same deck")
+	}
+}
@@ -0,0 +1,147 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Script 指定一手牌里的部分牌，没指定的从剩下的牌里随机
+type Script struct {
+	Seats int      // 这一手发几个人
+	Holes [][]Card // 按发牌顺序每个人的手牌，第 0 个是第一个发牌的人；可以少给，0 表示这张随机
+	Board []Card   // 公共牌，可以少给，0 表示这张随机
+}
+
+// ScriptedDealer 按 Script 摆好牌堆的发牌器，用来搭测试场景，比如翻牌暗三对暗三、转牌河牌连中同花
+// 每次 Shuffle 先正常洗牌，再把指定的牌放到按 DealLayout 会发给对应的人的位置上，其它位置随机
+type ScriptedDealer struct {
+	*Dealer
+	layout DealLayout
+	script map[int]Card // 牌堆里的位置 → 指定的牌
+}
+
+// NewScriptedDealer 创建按 script 发牌的发牌器，rng 为 nil 时用 crypto/rand，layout 为 nil 时用 CasinoLayout
+// 指定的牌重复、超过一副牌的张数、不在牌堆里或者位置冲突时返回错误
+func NewScriptedDealer(deckNum int, rawDeck []Card, rng RNG, layout DealLayout, script *Script) (*ScriptedDealer, error) {
+	if rng == nil {
+		rng = NewCryptoRNG()
+	}
+	if layout == nil {
+		layout = CasinoLayout{}
+	}
+	d := &ScriptedDealer{
+		Dealer: NewDealerWithRNG(deckNum, rawDeck, rng),
+		layout: layout,
+	}
+	if err := d.SetScript(script); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetScript 更换下一次 Shuffle 用的 Script，出错时原来的 Script 不变
+func (d *ScriptedDealer) SetScript(script *Script) error {
+	positions, err := d.place(script)
+	if err != nil {
+		return err
+	}
+	d.DealerMutex.Lock()
+	d.script = positions
+	d.DealerMutex.Unlock()
+	return nil
+}
+
+// place 检查 script，算出每张指定的牌的位置
+func (d *ScriptedDealer) place(script *Script) (map[int]Card, error) {
+	positions := make(map[int]Card)
+	if script == nil {
+		return positions, nil
+	}
+	if script.Seats < 0 || len(script.Holes) > script.Seats {
+		return nil, fmt.Errorf("%d seats with hole cards for %d seats", len(script.Holes), script.Seats)
+	}
+	if len(script.Board) > HOLDEM_BOARD_CARDS {
+		return nil, fmt.Errorf("%d board cards", len(script.Board))
+	}
+
+	d.DealerMutex.RLock()
+	inDeck := make(map[Card]int)
+	for _, c := range d.raw {
+		inDeck[c]++
+	}
+	total := len(d.raw)
+	d.DealerMutex.RUnlock()
+	if need := d.layout.BoardPos(script.Seats, HOLDEM_BOARD_CARDS-1) + 1; need > total {
+		return nil, fmt.Errorf("%d seats need %d cards, deck has %d", script.Seats, need, total)
+	}
+
+	put := func(pos int, c Card, what string) error {
+		if c == 0 {
+			return nil
+		}
+		if inDeck[c] == 0 {
+			return fmt.Errorf("%s: card %v is not in the deck or used more than the deck has", what, c.Front())
+		}
+		if pos < 0 || pos >= total {
+			return fmt.Errorf("%s: position %d out of range", what, pos)
+		}
+		if old, ok := positions[pos]; ok {
+			return fmt.Errorf("%s: card %v conflicts with %v at position %d", what, c.Front(), old.Front(), pos)
+		}
+		inDeck[c]--
+		positions[pos] = c
+		return nil
+	}
+	for seat, hole := range script.Holes {
+		if len(hole) > HOLDEM_HOLE_CARDS {
+			return nil, fmt.Errorf("seat %d has %d hole cards", seat, len(hole))
+		}
+		for k, c := range hole {
+			if err := put(d.layout.HolePos(script.Seats, seat, k), c, fmt.Sprintf("seat %d", seat)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, c := range script.Board {
+		if err := put(d.layout.BoardPos(script.Seats, i), c, fmt.Sprintf("board %d", i)); err != nil {
+			return nil, err
+		}
+	}
+	return positions, nil
+}
+
+// Shuffle 洗牌并把指定的牌放到对应的位置
+func (d *ScriptedDealer) Shuffle() error {
+	d.DealerMutex.Lock()
+	defer d.DealerMutex.Unlock()
+
+	// 指定的牌各去掉一张，剩下的洗好之后按顺序填进没指定的位置
+	used := make(map[Card]int, len(d.script))
+	for _, c := range d.script {
+		used[c]++
+	}
+	rest := make([]Card, 0, len(d.raw))
+	for _, c := range d.raw {
+		if used[c] > 0 {
+			used[c]--
+			continue
+		}
+		rest = append(rest, c)
+	}
+	if err := _shuffle(rest, d.rng); err != nil {
+		return err
+	}
+	if len(rest)+len(d.script) != len(d.cards) {
+		return errors.New("script does not fit the deck")
+	}
+
+	for i := range d.cards {
+		if c, ok := d.script[i]; ok {
+			d.cards[i] = c
+			continue
+		}
+		d.cards[i], rest = rest[0], rest[1:]
+	}
+	d._reset()
+	return nil
+}
@@ -0,0 +1,127 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScriptedDealer(t *testing.T) {
+	// 4 个人，第 1 个人 AA，第 3 个人只指定一张，翻牌指定两张，河牌指定
+	aa, board := []Card{AceSpades, AceHearts}, []Card{KingDiamonds, QueenDiamonds}
+	script := &Script{
+		Seats: 4,
+		Holes: [][]Card{aa, nil, {AceClubs}},
+		Board: []Card{board[0], 0, board[1], 0, AceDiamonds},
+	}
+	d, err := NewScriptedDealer(1, Deck, NewSeededRNG(7), SeatBySeatLayout{}, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for hand := 0; hand < 20; hand++ {
+		if err := d.Shuffle(); err != nil {
+			t.Fatal(err)
+		}
+		cards := dealHand(t, d.Dealer)
+		if cards[0] != aa[0] || cards[1] != aa[1] || cards[4] != AceClubs {
+			t.Fatal("err hole cards", cards[:8])
+		}
+		if cards[8] != board[0] || cards[10] != board[1] || cards[12] != AceDiamonds {
+			t.Fatal("err board", cards[8:])
+		}
+		// 整副牌还是 52 张不重复
+		seen := make(map[Card]bool)
+		for i := 0; i < d.TotalPoker(); i++ {
+			c, _ := d.PokerAt(i)
+			if seen[c] {
+				t.Fatal("duplicate card", c)
+			}
+			seen[c] = true
+		}
+		if len(seen) != len(Deck) {
+			t.Fatal("err deck size", len(seen))
+		}
+	}
+}
+
+func TestScriptedDealerRandomRest(t *testing.T) {
+	script := &Script{Seats: 2, Holes: [][]Card{{AceSpades, AceHearts}}}
+	d, err := NewScriptedDealer(1, Deck, NewSeededRNG(1), nil, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Shuffle()
+	first := d.Trace()
+	d.Shuffle()
+	if d.Trace() == first {
+		t.Error("unscripted cards should be shuffled")
+	}
+}
+
+func TestScriptedDealerErrors(t *testing.T) {
+	ace := AceSpades
+	cases := []struct {
+		name   string
+		script Script
+		expect string
+	}{
+		{"duplicate", Script{Seats: 2, Holes: [][]Card{{ace}, {ace}}}, "not in the deck"},
+		{"hole and board", Script{Seats: 2, Holes: [][]Card{{ace}}, Board: []Card{ace}}, "not in the deck"},
+		{"not a card", Script{Seats: 2, Board: []Card{0x0f}}, "not in the deck"},
+		{"too many hole cards", Script{Seats: 2, Holes: [][]Card{{ace, AceHearts, AceClubs}}}, "hole cards"},
+		{"too many board cards", Script{Seats: 2, Board: make([]Card, 6)}, "board cards"},
+		{"too many seats", Script{Seats: 1, Holes: make([][]Card, 2)}, "seats"},
+		{"deck too small", Script{Seats: 24}, "deck has"},
+	}
+	for _, c := range cases {
+		_, err := NewScriptedDealer(1, Deck, NewSeededRNG(1), nil, &c.script)
+		if err == nil || !strings.Contains(err.Error(), c.expect) {
+			t.Errorf("%s: expect error %q, got %v", c.name, c.expect, err)
+		}
+	}
+
+	// 两副牌可以指定两张一样的
+	if _, err := NewScriptedDealer(2, Deck, NewSeededRNG(1), nil, &Script{Seats: 2, Holes: [][]Card{{ace}, {ace}}}); err != nil {
+		t.Error(err)
+	}
+
+	// 出错时原来的 Script 不变
+	d, _ := NewScriptedDealer(1, Deck, NewSeededRNG(1), nil, &Script{Seats: 2, Holes: [][]Card{{ace}}})
+	if err := d.SetScript(&Script{Seats: 2, Holes: [][]Card{{ace}, {ace}}}); err == nil {
+		t.Fatal("expect error")
+	}
+	d.Shuffle()
+	if c, _, _ := d.DealOne(); c != ace {
+		t.Error("script should be kept", c)
+	}
+}
+
+func TestScriptedDealerCasinoLayout(t *testing.T) {
+	script := &Script{
+		Seats: 3,
+		Holes: [][]Card{nil, {AceSpades, AceHearts}},
+		Board: []Card{KingSpades, 0, 0, KingHearts, KingClubs},
+	}
+	d, err := NewScriptedDealer(1, Deck, NewSeededRNG(7), nil, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Shuffle()
+	holes, err := DealHoleCards(d, "hole", []string{"1", "2", "3"}, HOLDEM_HOLE_CARDS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holes[1][0] != AceSpades || holes[1][1] != AceHearts {
+		t.Fatal("err hole cards", holes)
+	}
+	var board []Card
+	for _, n := range []int{3, 1, 1} {
+		cards, err := DealStreet(d, "board", n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		board = append(board, cards...)
+	}
+	if board[0] != KingSpades || board[3] != KingHearts || board[4] != KingClubs {
+		t.Fatal("err board", board)
+	}
+}
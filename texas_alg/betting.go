@@ -0,0 +1,418 @@
+package texas_holdem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ActionType 玩家的动作
+type ActionType int
+
+const (
+	ActionFold  ActionType = iota // 弃牌
+	ActionCheck                   // 过牌
+	ActionCall                    // 跟注
+	ActionBet                     // 下注
+	ActionRaise                   // 加注
+	ActionAllIn                   // 全下
+)
+
+var actionName = []string{"fold", "check", "call", "bet", "raise", "allin"}
+
+func (a ActionType) String() string {
+	if a >= 0 && int(a) < len(actionName) {
+		return actionName[a]
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Action 玩家做出的动作，Bet 和 Raise 的 Amount 是这一轮加到的总额（raise to），其它动作不用填
+type Action struct {
+	Type   ActionType
+	Amount int64
+}
+
+// LegalAction 当前可以做的动作，Min Max 是 Amount 的范围
+// Call 和 AllIn 的 Min Max 是要放进去的筹码数
+type LegalAction struct {
+	Type ActionType
+	Min  int64
+	Max  int64
+}
+
+// 动作不合法的原因，用 errors.Is 判断
+var (
+	ErrRoundClosed   = errors.New("betting round is closed")
+	ErrNotYourTurn   = errors.New("not your turn")
+	ErrUnknownAction = errors.New("unknown action")
+	ErrCannotCheck   = errors.New("cannot check facing a bet")
+	ErrNothingToCall = errors.New("nothing to call")
+	ErrCannotBet     = errors.New("cannot bet facing a bet, raise instead")
+	ErrCannotRaise   = errors.New("cannot raise without a bet, bet instead")
+	ErrBelowMinRaise = errors.New("bet or raise below the minimum")
+	ErrAboveStack    = errors.New("bet or raise more than the stack")
+	ErrAboveLimit    = errors.New("bet or raise above the limit")
+	ErrRaiseCapped   = errors.New("betting is capped on this street")
+	ErrRaiseClosed   = errors.New("action is not reopened, only call or fold")
+	ErrNoChips       = errors.New("no chips left")
+)
+
+// ActionError 被拒绝的动作，Err 是上面的某一个原因
+type ActionError struct {
+	SeatID int32
+	Action Action
+	Err    error
+	Min    int64 // 允许的最小值（如果有）
+	Max    int64 // 允许的最大值（如果有）
+}
+
+func (e *ActionError) Error() string {
+	if e.Min != 0 || e.Max != 0 {
+		return fmt.Sprintf("seat %d %v %d: %v (min %d max %d)", e.SeatID, e.Action.Type, e.Action.Amount, e.Err, e.Min, e.Max)
+	}
+	return fmt.Sprintf("seat %d %v: %v", e.SeatID, e.Action.Type, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// BettingSeat 下注轮里一个座位的状态
+type BettingSeat struct {
+	SeatID    int32
+	Stack     int64 // 剩余的筹码
+	Folded    bool  // 已经弃牌
+	AllIn     bool  // 已经全下
+	StreetBet int64 // 这一轮下注的金额（包括盲注）
+	TotalBet  int64 // 这一手一共下注的金额（包括前注）
+	DeadBet   int64 // 这一手下的死钱（补的小盲、替别人下的前注），在底池里但不算他的下注
+
+	acted    bool  // 这一轮是否行动过
+	actedAt  int64 // 最后一次行动时这一轮的最高下注
+	canRaise bool  // 轮到他时是否还可以加注
+}
+
+func (s *BettingSeat) canAct() bool {
+	return !s.Folded && !s.AllIn
+}
+
+func (s *BettingSeat) putChips(amount int64) {
+	s.StreetBet += amount
+	s.putAnte(amount)
+}
+
+// putAnte 前注算在这一手的下注里，但不算这一轮的下注，别人不用跟
+func (s *BettingSeat) putAnte(amount int64) {
+	s.TotalBet += amount
+	s.takeStack(amount)
+}
+
+// putDead 死钱放进底池，不算下注
+func (s *BettingSeat) putDead(amount int64) {
+	s.DeadBet += amount
+	s.takeStack(amount)
+}
+
+func (s *BettingSeat) takeStack(amount int64) {
+	s.Stack -= amount
+	if s.Stack == 0 {
+		s.AllIn = true
+	}
+}
+
+// BettingRound 一轮下注，下注的大小和次数由 BettingStructure 限制
+// 最小加注幅度是这一轮上一次完整加注的幅度（至少一个 BetUnit）；
+// 全下但不够一次完整加注时不重新开放加注，已经行动过的人只能跟注或者弃牌，
+// 除非之后累计加上去的幅度够一次完整加注；
+// 翻牌前大盲就算所有人都平跟也还可以选择过牌或者加注
+type BettingRound struct {
+	seats      []*BettingSeat // 按行动顺序排好
+	structure  BettingStructure
+	street     Stage
+	currentBet int64 // 这一轮最高的下注
+	minRaise   int64 // 最小加注的幅度
+	raises     int   // 这一轮完整下注加注的次数
+	turn       int   // 轮到 seats 里的哪一个，-1 表示这一轮结束
+}
+
+// NewBettingRound 开始一轮无限注的下注，first 是 seats 里第一个行动的下标
+// seats 里已经下的盲注算在 StreetBet 里，这一轮最高下注就是 StreetBet 的最大值
+func NewBettingRound(seats []*BettingSeat, first int, bigBlind int64) *BettingRound {
+	return NewBettingRoundWithStructure(seats, first, NoLimit{BigBlind: bigBlind}, StagePreflop)
+}
+
+// NewBettingRoundWithStructure 按下注结构开始 street 这条街的一轮下注
+// 已经有人下了盲注、抓头时，每个不小于 BetUnit 的不同金额算一次下注加注
+func NewBettingRoundWithStructure(seats []*BettingSeat, first int, structure BettingStructure, street Stage) *BettingRound {
+	r := &BettingRound{
+		seats:     seats,
+		structure: structure,
+		street:    street,
+	}
+	unit := structure.BetUnit(street)
+	levels := make(map[int64]bool)
+	for _, s := range seats {
+		s.acted = false
+		s.actedAt = 0
+		if s.StreetBet > r.currentBet {
+			r.currentBet = s.StreetBet
+		}
+		if s.StreetBet >= unit && !levels[s.StreetBet] {
+			levels[s.StreetBet] = true
+			r.raises++
+		}
+	}
+	r.minRaise = unit
+	if r.currentBet > 0 {
+		r.minRaise = structure.OpenRaise(street, r.currentBet)
+	}
+	r.nextTurn(first - 1)
+	return r
+}
+
+// Closed 这一轮下注是否结束
+func (r *BettingRound) Closed() bool {
+	return r.turn < 0
+}
+
+// Turn 轮到哪个座位行动
+func (r *BettingRound) Turn() (seatID int32, ok bool) {
+	if r.turn < 0 {
+		return 0, false
+	}
+	return r.seats[r.turn].SeatID, true
+}
+
+// CurrentBet 这一轮最高的下注
+func (r *BettingRound) CurrentBet() int64 {
+	return r.currentBet
+}
+
+// MinRaiseTo 现在加注最少要加到多少
+func (r *BettingRound) MinRaiseTo() int64 {
+	return r.currentBet + r.minRaise
+}
+
+// MaxRaiseTo 现在轮到的座位按下注结构最多可以加注到多少，不考虑筹码
+func (r *BettingRound) MaxRaiseTo() int64 {
+	if r.turn < 0 {
+		return 0
+	}
+	s := r.seats[r.turn]
+	limit := r.structure.MaxRaiseTo(r.street, r.currentBet, r.currentBet-s.StreetBet, r.Pot())
+	if minTo := r.MinRaiseTo(); limit < minTo {
+		return minTo
+	}
+	return limit
+}
+
+// Pot 底池，包括这一轮已经下注的金额和死钱
+func (r *BettingRound) Pot() int64 {
+	pot := int64(0)
+	for _, s := range r.seats {
+		pot += s.TotalBet + s.DeadBet
+	}
+	return pot
+}
+
+// DeadMoney 所有座位下的死钱，分配底池时用 PondOptions.DeadMoney 放进主池
+func (r *BettingRound) DeadMoney() int64 {
+	dead := int64(0)
+	for _, s := range r.seats {
+		dead += s.DeadBet
+	}
+	return dead
+}
+
+// Capped 这一轮下注加注的次数是否已经到了上限
+func (r *BettingRound) Capped() bool {
+	maxRaises := r.structure.RaiseCap()
+	return maxRaises > 0 && r.raises >= maxRaises
+}
+
+// Committed 每个座位这一手一共下注的金额
+func (r *BettingRound) Committed() map[int32]int64 {
+	res := make(map[int32]int64, len(r.seats))
+	for _, s := range r.seats {
+		res[s.SeatID] = s.TotalBet
+	}
+	return res
+}
+
+// LegalActions 当前轮到的座位可以做的动作
+func (r *BettingRound) LegalActions() []LegalAction {
+	if r.turn < 0 {
+		return nil
+	}
+	s := r.seats[r.turn]
+	toCall := r.currentBet - s.StreetBet
+	maxTo := s.StreetBet + s.Stack
+
+	res := []LegalAction{{Type: ActionFold}}
+	if toCall <= 0 {
+		res = append(res, LegalAction{Type: ActionCheck})
+	} else {
+		call := _min64(toCall, s.Stack)
+		res = append(res, LegalAction{Type: ActionCall, Min: call, Max: call})
+	}
+	limit := r.MaxRaiseTo()
+	if !s.canRaise || r.Capped() || maxTo <= r.currentBet {
+		// 只能跟注的时候，筹码不够跟注才可以全下
+		if s.Stack > 0 && s.Stack <= toCall {
+			res = append(res, LegalAction{Type: ActionAllIn, Min: s.Stack, Max: s.Stack})
+		}
+		return res
+	}
+	if minTo := r.MinRaiseTo(); maxTo > minTo {
+		typ := ActionRaise
+		if r.currentBet == 0 {
+			typ = ActionBet
+		}
+		res = append(res, LegalAction{Type: typ, Min: minTo, Max: _min64(maxTo, limit)})
+	}
+	if maxTo <= limit {
+		res = append(res, LegalAction{Type: ActionAllIn, Min: s.Stack, Max: s.Stack})
+	}
+	return res
+}
+
+// Act 轮到的座位行动，不合法时返回 *ActionError，状态不变
+func (r *BettingRound) Act(seatID int32, a Action) error {
+	reject := func(err error, min, max int64) error {
+		return &ActionError{SeatID: seatID, Action: a, Err: err, Min: min, Max: max}
+	}
+	if r.turn < 0 {
+		return reject(ErrRoundClosed, 0, 0)
+	}
+	s := r.seats[r.turn]
+	if s.SeatID != seatID {
+		return reject(ErrNotYourTurn, 0, 0)
+	}
+
+	toCall := r.currentBet - s.StreetBet
+	maxTo := s.StreetBet + s.Stack
+	switch a.Type {
+	case ActionFold:
+		s.Folded = true
+	case ActionCheck:
+		if toCall > 0 {
+			return reject(ErrCannotCheck, 0, 0)
+		}
+	case ActionCall:
+		if toCall <= 0 {
+			return reject(ErrNothingToCall, 0, 0)
+		}
+		s.putChips(_min64(toCall, s.Stack))
+	case ActionBet, ActionRaise:
+		if a.Type == ActionBet && r.currentBet > 0 {
+			return reject(ErrCannotBet, 0, 0)
+		}
+		if a.Type == ActionRaise && r.currentBet == 0 {
+			return reject(ErrCannotRaise, 0, 0)
+		}
+		if err := r.checkRaise(s); err != nil {
+			return reject(err, 0, 0)
+		}
+		limit := r.MaxRaiseTo()
+		if a.Amount > maxTo {
+			return reject(ErrAboveStack, r.MinRaiseTo(), _min64(maxTo, limit))
+		}
+		if a.Amount > limit {
+			return reject(ErrAboveLimit, r.MinRaiseTo(), limit)
+		}
+		// 筹码不够最小加注时只能全下
+		if a.Amount <= r.currentBet || (a.Amount < r.MinRaiseTo() && a.Amount != maxTo) {
+			return reject(ErrBelowMinRaise, r.MinRaiseTo(), _min64(maxTo, limit))
+		}
+		r.raiseTo(s, a.Amount)
+	case ActionAllIn:
+		if s.Stack == 0 {
+			return reject(ErrNoChips, 0, 0)
+		}
+		if maxTo > r.currentBet {
+			if err := r.checkRaise(s); err != nil {
+				return reject(err, 0, 0)
+			}
+			if limit := r.MaxRaiseTo(); maxTo > limit {
+				return reject(ErrAboveLimit, 0, limit)
+			}
+			r.raiseTo(s, maxTo)
+		} else {
+			s.putChips(s.Stack)
+		}
+	default:
+		return reject(ErrUnknownAction, 0, 0)
+	}
+	s.acted = true
+	s.actedAt = r.currentBet
+
+	r.nextTurn(r.turn)
+	return nil
+}
+
+// checkRaise 轮到的座位现在能不能加注
+func (r *BettingRound) checkRaise(s *BettingSeat) error {
+	if !s.canRaise {
+		return ErrRaiseClosed
+	}
+	if r.Capped() {
+		return ErrRaiseCapped
+	}
+	return nil
+}
+
+// raiseTo 加注到 to，够一次完整加注时更新最小加注幅度和加注次数
+func (r *BettingRound) raiseTo(s *BettingSeat, to int64) {
+	if raise := to - r.currentBet; raise >= r.minRaise {
+		r.minRaise = raise
+		r.raises++
+	}
+	r.currentBet = to
+	s.putChips(to - s.StreetBet)
+}
+
+// nextTurn 从 seats[from] 的下一个开始找还需要行动的座位
+func (r *BettingRound) nextTurn(from int) {
+	r.turn = -1
+	live, canAct := 0, 0
+	for _, s := range r.seats {
+		if !s.Folded {
+			live++
+		}
+		if s.canAct() {
+			canAct++
+		}
+	}
+	if live < 2 {
+		return
+	}
+	for i := 1; i <= len(r.seats); i++ {
+		idx := (from + i + len(r.seats)) % len(r.seats)
+		s := r.seats[idx]
+		if !s.canAct() || (s.acted && s.StreetBet >= r.currentBet) {
+			continue
+		}
+		// 其他人都不能行动时，已经跟平的人不需要再行动
+		if s.StreetBet >= r.currentBet && canAct < 2 {
+			continue
+		}
+		// 上次行动之后被加注的幅度够一次完整加注，才可以再加注
+		s.canRaise = !s.acted || r.currentBet-s.actedAt >= r.minRaise
+		r.turn = idx
+		return
+	}
+}
+
+// BetStatus 把每个座位这一手的下注转成 DistributePond 用的 IBetStatus
+// winVal 返回座位的 WinVal，弃牌的座位固定为 0
+func (r *BettingRound) BetStatus(winVal func(seatID int32) uint32) []IBetStatus {
+	res := make([]IBetStatus, len(r.seats))
+	for i, s := range r.seats {
+		val := uint32(0)
+		if !s.Folded {
+			val = winVal(s.SeatID)
+		}
+		res[i] = NewBetStatus(s.SeatID, val, s.TotalBet)
+	}
+	return res
+}
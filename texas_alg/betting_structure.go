@@ -0,0 +1,91 @@
+package texas_holdem
+
+import "math"
+
+// FIXED_LIMIT_DEFAULT_CAP 限注每条街默认最多一次下注加三次加注
+const FIXED_LIMIT_DEFAULT_CAP = 4
+
+// BettingStructure 下注结构，决定每次下注加注的大小和次数
+type BettingStructure interface {
+	// BetUnit 这条街下注的最小幅度，也是第一次加注的最小幅度
+	BetUnit(street Stage) int64
+	// OpenRaise 一轮开始时已经有盲注、抓头时的最小加注幅度，currentBet 是其中最大的
+	OpenRaise(street Stage, currentBet int64) int64
+	// MaxRaiseTo 不考虑筹码时最多可以加注到多少
+	// currentBet 是这一轮最高的下注，toCall 是行动的人要跟的金额，pot 是包括这一轮下注在内的底池
+	MaxRaiseTo(street Stage, currentBet, toCall, pot int64) int64
+	// RaiseCap 每条街最多几次下注加注（下注和翻牌前的大盲都算一次），0 表示不限
+	RaiseCap() int
+}
+
+// NoLimit 无限注，最多可以全下
+type NoLimit struct {
+	BigBlind int64
+}
+
+func (l NoLimit) BetUnit(street Stage) int64 {
+	return l.BigBlind
+}
+
+func (l NoLimit) OpenRaise(street Stage, currentBet int64) int64 {
+	return _max64(l.BigBlind, currentBet)
+}
+
+func (l NoLimit) MaxRaiseTo(street Stage, currentBet, toCall, pot int64) int64 {
+	return math.MaxInt64
+}
+
+func (l NoLimit) RaiseCap() int {
+	return 0
+}
+
+// PotLimit 底池限注，最多加注到：先跟注，再加上跟注之后底池那么多
+type PotLimit struct {
+	BigBlind int64
+}
+
+func (l PotLimit) BetUnit(street Stage) int64 {
+	return l.BigBlind
+}
+
+func (l PotLimit) OpenRaise(street Stage, currentBet int64) int64 {
+	return _max64(l.BigBlind, currentBet)
+}
+
+func (l PotLimit) MaxRaiseTo(street Stage, currentBet, toCall, pot int64) int64 {
+	return currentBet + pot + toCall
+}
+
+func (l PotLimit) RaiseCap() int {
+	return 0
+}
+
+// FixedLimit 固定限注，翻牌前和翻牌圈按小注，转牌圈和河牌圈按大注，每次下注加注都是固定的大小
+type FixedLimit struct {
+	SmallBet int64
+	BigBet   int64
+	Cap      int // 每条街最多几次下注加注，0 表示 FIXED_LIMIT_DEFAULT_CAP
+}
+
+func (l FixedLimit) BetUnit(street Stage) int64 {
+	if street >= StageTurn {
+		return l.BigBet
+	}
+	return l.SmallBet
+}
+
+// OpenRaise 限注里抓头也算一次加注，之后每次还是加一个 BetUnit
+func (l FixedLimit) OpenRaise(street Stage, currentBet int64) int64 {
+	return l.BetUnit(street)
+}
+
+func (l FixedLimit) MaxRaiseTo(street Stage, currentBet, toCall, pot int64) int64 {
+	return currentBet + l.BetUnit(street)
+}
+
+func (l FixedLimit) RaiseCap() int {
+	if l.Cap == 0 {
+		return FIXED_LIMIT_DEFAULT_CAP
+	}
+	return l.Cap
+}
@@ -0,0 +1,154 @@
+package texas_holdem
+
+import (
+	"errors"
+	"testing"
+)
+
+func newPreflopRound(structure BettingStructure, sb, bb int64, stacks ...int64) *BettingRound {
+	seats := make([]*BettingSeat, len(stacks))
+	for i, stack := range stacks {
+		seats[i] = &BettingSeat{SeatID: int32(i + 1), Stack: stack}
+	}
+	seats[0].putChips(sb)
+	seats[1].putChips(bb)
+	first := 2 % len(seats)
+	return NewBettingRoundWithStructure(seats, first, structure, StagePreflop)
+}
+
+func TestPotLimitMultiWay(t *testing.T) {
+	// 1 号小盲 1，2 号大盲 2，3~5 号依次行动
+	r := newPreflopRound(PotLimit{BigBlind: 2}, 1, 2, 1000, 1000, 1000, 1000, 1000)
+
+	steps := []struct {
+		seatID int32
+		maxTo  int64
+		action Action
+	}{
+		// 底池 3，跟 2 之后底池 5，最多加到 7
+		{3, 7, Action{Type: ActionRaise, Amount: 7}},
+		// 底池 10，跟 7 之后底池 17，最多加到 24
+		{4, 24, Action{Type: ActionRaise, Amount: 24}},
+		// 底池 34，跟 24 之后底池 58，最多加到 82
+		{5, 82, Action{Type: ActionCall}},
+		// 小盲已经下了 1：底池 58，跟 23 之后底池 81，最多加到 105
+		{1, 105, Action{Type: ActionRaise, Amount: 105}},
+		// 大盲已经下了 2：底池 162，跟 103 之后底池 265，最多加到 370
+		{2, 370, Action{Type: ActionFold}},
+		// 3 号已经下了 7：底池 162，跟 98 之后底池 260，最多加到 365
+		{3, 365, Action{Type: ActionCall}},
+		// 大盲弃牌的钱也算在底池里：底池 260，跟 81 之后底池 341，最多加到 446
+		{4, 446, Action{Type: ActionCall}},
+		{5, 527, Action{Type: ActionCall}},
+	}
+	for i, step := range steps {
+		if seatID, _ := r.Turn(); seatID != step.seatID {
+			t.Fatal("step", i, "err turn", seatID)
+		}
+		if r.MaxRaiseTo() != step.maxTo {
+			t.Error("step", i, "err max raise", r.MaxRaiseTo(), step.maxTo)
+		}
+		if err := r.Act(step.seatID, Action{Type: ActionRaise, Amount: step.maxTo + 1}); !errors.Is(err, ErrAboveLimit) {
+			t.Error("step", i, "err", err)
+		}
+		mustBet(t, r, step.seatID, step.action)
+	}
+	if !r.Closed() || r.Pot() != 105*4+2 {
+		t.Error("err pot", r.Pot())
+	}
+}
+
+func TestPotLimitPostflopAllIn(t *testing.T) {
+	seats := []*BettingSeat{
+		{SeatID: 1, Stack: 1000, TotalBet: 20},
+		{SeatID: 2, Stack: 50, TotalBet: 20},
+		{SeatID: 3, Stack: 1000, TotalBet: 20},
+	}
+	r := NewBettingRoundWithStructure(seats, 0, PotLimit{BigBlind: 10}, StageFlop)
+
+	// 底池 60，最多下注 60
+	if r.MaxRaiseTo() != 60 {
+		t.Error("err max bet", r.MaxRaiseTo())
+	}
+	if hasAction(r.LegalActions(), ActionAllIn) {
+		t.Error("all in above pot should not be legal", r.LegalActions())
+	}
+	if err := r.Act(1, Action{Type: ActionAllIn}); !errors.Is(err, ErrAboveLimit) {
+		t.Error("err", err)
+	}
+	mustBet(t, r, 1, Action{Type: ActionBet, Amount: 20})
+	// 2 号筹码不够一个底池，可以全下
+	mustBet(t, r, 2, Action{Type: ActionAllIn})
+	// 底池 130，跟 50 之后底池 180，最多加到 230
+	if r.MaxRaiseTo() != 230 || r.MinRaiseTo() != 80 {
+		t.Error("err raise range", r.MinRaiseTo(), r.MaxRaiseTo())
+	}
+	mustBet(t, r, 3, Action{Type: ActionRaise, Amount: 230})
+	mustBet(t, r, 1, Action{Type: ActionCall})
+	if !r.Closed() {
+		t.Error("round should be closed")
+	}
+	committed := r.Committed()
+	if committed[1] != 250 || committed[2] != 70 || committed[3] != 250 {
+		t.Error("err committed", committed)
+	}
+}
+
+func TestFixedLimitCap(t *testing.T) {
+	limit := FixedLimit{SmallBet: 2, BigBet: 4}
+	r := newPreflopRound(limit, 1, 2, 100, 100, 100)
+
+	// 大盲算第一次下注，再加注三次就封顶
+	if r.MinRaiseTo() != 4 || r.MaxRaiseTo() != 4 {
+		t.Error("err raise range", r.MinRaiseTo(), r.MaxRaiseTo())
+	}
+	if err := r.Act(3, Action{Type: ActionRaise, Amount: 6}); !errors.Is(err, ErrAboveLimit) {
+		t.Error("err", err)
+	}
+	if hasAction(r.LegalActions(), ActionAllIn) {
+		t.Error("all in should not be legal in fixed limit", r.LegalActions())
+	}
+	mustBet(t, r, 3, Action{Type: ActionRaise, Amount: 4})
+	mustBet(t, r, 1, Action{Type: ActionRaise, Amount: 6})
+	mustBet(t, r, 2, Action{Type: ActionRaise, Amount: 8})
+	if !r.Capped() {
+		t.Error("should be capped")
+	}
+	if hasAction(r.LegalActions(), ActionRaise) {
+		t.Error("raise should not be legal after cap", r.LegalActions())
+	}
+	if err := r.Act(3, Action{Type: ActionRaise, Amount: 10}); !errors.Is(err, ErrRaiseCapped) {
+		t.Error("err", err)
+	}
+	mustBet(t, r, 3, Action{Type: ActionCall})
+	mustBet(t, r, 1, Action{Type: ActionCall})
+	if !r.Closed() {
+		t.Error("round should be closed")
+	}
+
+	// 转牌圈按大注
+	seats := []*BettingSeat{{SeatID: 1, Stack: 92}, {SeatID: 2, Stack: 92}}
+	r = NewBettingRoundWithStructure(seats, 0, limit, StageTurn)
+	if r.MinRaiseTo() != 4 || r.MaxRaiseTo() != 4 {
+		t.Error("err turn bet", r.MinRaiseTo(), r.MaxRaiseTo())
+	}
+}
+
+func TestTableFixedLimit(t *testing.T) {
+	table := newTestTable(t, 100, 100, 100)
+	table.config.Structure = FixedLimit{SmallBet: 10, BigBet: 20}
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	// 翻牌前庄家 1 号先行动，然后小盲 2 号，大盲 3 号
+	mustAct(t, table, 1, Action{Type: ActionCall})
+	mustAct(t, table, 2, Action{Type: ActionCall})
+	mustAct(t, table, 3, Action{Type: ActionCheck})
+	if table.Stage() != StageFlop {
+		t.Fatal("err stage", table.Stage())
+	}
+	legal := table.LegalActions()
+	if !hasAction(legal, ActionBet) || legal[len(legal)-1].Type != ActionBet || legal[len(legal)-1].Max != 10 {
+		t.Error("err flop legal actions", legal)
+	}
+}
@@ -0,0 +1,140 @@
+package texas_holdem
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestRound(first int, bigBlind int64, stacks ...int64) (*BettingRound, []*BettingSeat) {
+	seats := make([]*BettingSeat, len(stacks))
+	for i, stack := range stacks {
+		seats[i] = &BettingSeat{SeatID: int32(i + 1), Stack: stack}
+	}
+	return NewBettingRound(seats, first, bigBlind), seats
+}
+
+func mustBet(t *testing.T, r *BettingRound, seatID int32, a Action) {
+	t.Helper()
+	if err := r.Act(seatID, a); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasAction(legal []LegalAction, typ ActionType) bool {
+	for _, l := range legal {
+		if l.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBettingMinRaise(t *testing.T) {
+	r, _ := newTestRound(0, 100, 1000, 1000, 1000)
+
+	err := r.Act(1, Action{Type: ActionBet, Amount: 50})
+	var actionErr *ActionError
+	if !errors.Is(err, ErrBelowMinRaise) || !errors.As(err, &actionErr) || actionErr.Min != 100 {
+		t.Error("bet below big blind should fail", err)
+	}
+	if err := r.Act(2, Action{Type: ActionCheck}); !errors.Is(err, ErrNotYourTurn) {
+		t.Error("err", err)
+	}
+	mustBet(t, r, 1, Action{Type: ActionBet, Amount: 100})
+	if err := r.Act(2, Action{Type: ActionCheck}); !errors.Is(err, ErrCannotCheck) {
+		t.Error("err", err)
+	}
+	mustBet(t, r, 2, Action{Type: ActionRaise, Amount: 350})
+	// 上一次加注幅度是 250，最少加到 600
+	if err := r.Act(3, Action{Type: ActionRaise, Amount: 500}); !errors.Is(err, ErrBelowMinRaise) {
+		t.Error("err", err)
+	}
+	if err := r.Act(3, Action{Type: ActionRaise, Amount: 1100}); !errors.Is(err, ErrAboveStack) {
+		t.Error("err", err)
+	}
+	if r.MinRaiseTo() != 600 {
+		t.Error("err min raise", r.MinRaiseTo())
+	}
+	mustBet(t, r, 3, Action{Type: ActionFold})
+	mustBet(t, r, 1, Action{Type: ActionCall})
+	if !r.Closed() {
+		t.Error("round should be closed")
+	}
+}
+
+func TestBettingShortAllIn(t *testing.T) {
+	r, seats := newTestRound(0, 100, 1000, 1000, 130)
+
+	mustBet(t, r, 1, Action{Type: ActionBet, Amount: 100})
+	mustBet(t, r, 2, Action{Type: ActionCall})
+	mustBet(t, r, 3, Action{Type: ActionAllIn})
+
+	// 3 号全下只多了 30，不够一次完整加注，1 号和 2 号只能跟注或者弃牌
+	if hasAction(r.LegalActions(), ActionRaise) || hasAction(r.LegalActions(), ActionAllIn) {
+		t.Error("action should not be reopened", r.LegalActions())
+	}
+	if err := r.Act(1, Action{Type: ActionRaise, Amount: 300}); !errors.Is(err, ErrRaiseClosed) {
+		t.Error("err", err)
+	}
+	mustBet(t, r, 1, Action{Type: ActionCall})
+	mustBet(t, r, 2, Action{Type: ActionCall})
+	if !r.Closed() {
+		t.Error("round should be closed")
+	}
+
+	for _, s := range seats {
+		if s.TotalBet != 130 {
+			t.Error("err committed", s)
+		}
+	}
+	win := DistributePond(r.BetStatus(func(seatID int32) uint32 { return uint32(seatID) }))
+	if win[3] != 390 {
+		t.Error("err distribute", win)
+	}
+}
+
+func TestBettingShortAllInAfterFullRaise(t *testing.T) {
+	r, _ := newTestRound(0, 100, 1000, 1000, 400)
+
+	mustBet(t, r, 1, Action{Type: ActionBet, Amount: 100})
+	mustBet(t, r, 2, Action{Type: ActionRaise, Amount: 300})
+	mustBet(t, r, 3, Action{Type: ActionAllIn})
+
+	// 1 号还没对 2 号的完整加注行动过，可以再加注
+	if !hasAction(r.LegalActions(), ActionRaise) {
+		t.Error("seat 1 should be able to raise", r.LegalActions())
+	}
+	mustBet(t, r, 1, Action{Type: ActionCall})
+	// 2 号之后只被多加了 100，不能再加注
+	if hasAction(r.LegalActions(), ActionRaise) {
+		t.Error("seat 2 should not be able to raise", r.LegalActions())
+	}
+	mustBet(t, r, 2, Action{Type: ActionCall})
+	if !r.Closed() {
+		t.Error("round should be closed")
+	}
+}
+
+func TestBettingBigBlindOption(t *testing.T) {
+	seats := []*BettingSeat{
+		{SeatID: 1, Stack: 995, StreetBet: 5, TotalBet: 5},
+		{SeatID: 2, Stack: 990, StreetBet: 10, TotalBet: 10},
+		{SeatID: 3, Stack: 1000},
+	}
+	r := NewBettingRound(seats, 2, 10)
+	mustBet(t, r, 3, Action{Type: ActionCall})
+	mustBet(t, r, 1, Action{Type: ActionCall})
+
+	// 所有人平跟，大盲还可以选择
+	if seatID, ok := r.Turn(); !ok || seatID != 2 {
+		t.Fatal("big blind should have the option", seatID, ok)
+	}
+	legal := r.LegalActions()
+	if !hasAction(legal, ActionCheck) || !hasAction(legal, ActionRaise) {
+		t.Error("err legal actions", legal)
+	}
+	mustBet(t, r, 2, Action{Type: ActionCheck})
+	if !r.Closed() {
+		t.Error("round should be closed")
+	}
+}
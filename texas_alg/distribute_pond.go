@@ -3,6 +3,8 @@ package texas_holdem
 import (
 	"fmt"
 	"sort"
+
+	"github.com/zack-wong/TexasDemo/poker"
 )
 
 type IBetStatus interface {
@@ -12,69 +14,365 @@ type IBetStatus interface {
 }
 type SeatID2WinAmount map[int32]int64
 
+// OddChipRule 底池不能被赢家平分时，多出来的零头给谁
+type OddChipRule int
+
+const (
+	OddChipLowestSeat   OddChipRule = iota // 座位号最小的赢家优先
+	OddChipLeftOfButton                    // 从庄家左手边开始顺时针第一个赢家优先
+	OddChipHighestSuit                     // 手牌里单张最大的赢家优先，牌值相同比花色 ♠ > ♥ > ♦ > ♣
+)
+
+// PondOptions 分配底池的可选参数
+type PondOptions struct {
+	OddChip    OddChipRule
+	ButtonSeat int32                  // OddChipLeftOfButton 时庄家的座位号
+	HoleCards  map[int32][]poker.Card // OddChipHighestSuit 时各座位的手牌
+	Rake       *RakePolicy            // 抽水规则，nil 不抽水
+	FlopSeen   bool                   // 这手牌是否发了翻牌，RakePolicy.NoFlopNoDrop 用
+	DeadMoney  int64                  // 不算任何座位下注的死钱（大盲前注、补的小盲），放进主池
+}
+
+// RAKE_RATE_BASE 抽水比例的单位，万分比
+const RAKE_RATE_BASE = 10000
+
+// RakePolicy 抽水规则，按主池、边池的顺序从每个底池里抽，抽完再分给赢家
+type RakePolicy struct {
+	Rate          int64 // 抽水比例，万分比，500 表示 5%，不足 1 的部分舍去
+	Cap           int64 // 一手牌最多抽多少，0 表示不封顶
+	NoFlopNoDrop  bool  // 没发翻牌就不抽水（也不抽奖池）
+	JackpotDrop   int64 // 每手放进坏牌奖池的金额，从主池里抽，0 表示不抽
+	JackpotMinPot int64 // 所有底池加起来达到这个数才抽奖池
+}
+
+// OddChip 零头分配记录，一个底池的零头按规则排好的顺序每人一个
+type OddChip struct {
+	SeatID int32
+	Amount int64
+}
+
+// Pot 一个底池（主池或者边池）
+type Pot struct {
+	Amount   int64     // 底池金额，包含抽水
+	Rake     int64     // 这个底池的抽水
+	Jackpot  int64     // 这个底池放进坏牌奖池的金额
+	Dead     int64     // 这个底池里的死钱，已经算在 Amount 里
+	Eligible []int32   // 有资格赢这个底池的座位
+	Winners  []int32   // 赢家
+	Share    int64     // 每个赢家平分到的金额，不含零头
+	OddChips []OddChip // 零头的去向
+
+	contributors int // 往这个底池下注的座位数
+}
+
+// UncalledBet 没有人跟的下注，在组底池之前退还给下注的人
+type UncalledBet struct {
+	SeatID int32
+	Amount int64
+}
+
+// PondResult 底池分配的明细
+type PondResult struct {
+	Uncalled  *UncalledBet     // 退还的没人跟的下注，没有则为 nil
+	Pots      []*Pot           // Pots[0] 是主池，后面依次是边池
+	Winnings  SeatID2WinAmount // 每个座位拿回的金额，包括退还的下注
+	Rake      int64            // 所有底池的抽水之和
+	Jackpot   int64            // 所有底池放进坏牌奖池的金额之和
+	Unclaimed int64            // 没有人有资格赢的金额（只有死钱、没有任何座位时），由调用方决定退给谁
+}
+
 func DistributePond(inputs []IBetStatus) SeatID2WinAmount {
-	seatID2Win := make(SeatID2WinAmount)
+	return DistributePondDetail(inputs, nil).Winnings
+}
+
+// DistributePondWithOptions 分配底池，返回每个座位赢到的金额和零头的去向
+func DistributePondWithOptions(inputs []IBetStatus, opt *PondOptions) (SeatID2WinAmount, []OddChip) {
+	res := DistributePondDetail(inputs, opt)
+	var oddChips []OddChip
+	for _, pot := range res.Pots {
+		oddChips = append(oddChips, pot.OddChips...)
+	}
+	return res.Winnings, oddChips
+}
+
+// DistributePondDetail 分配底池并列出主池和每个边池，
+// 赢到的总额加上抽水、奖池和 Unclaimed 等于下注总额加上 opt.DeadMoney
+// 下注最多的人超出第二多的部分没有人跟，不管他有没有弃牌，都先退还给他，不进入底池
+// 按没弃牌（WinVal 不为 0）的座位的下注额分层：每一层是一个底池，下注额达到这一层的没弃牌座位有资格赢；
+// 弃牌座位的下注按同样的分层放进各个底池，超过最高一层的部分放进最后一个底池
+// opt.DeadMoney 放进主池；有没弃牌但下注额为 0 的座位（只下了死钱，比如下完前注就全下了）或者没有别的底池时，
+// 死钱单独作为主池，所有没弃牌的座位都有资格赢
+// opt 为 nil 时零头按 OddChipLowestSeat 分配
+func DistributePondDetail(inputs []IBetStatus, opt *PondOptions) *PondResult {
+	if opt == nil {
+		opt = &PondOptions{}
+	}
+	res := &PondResult{Winnings: make(SeatID2WinAmount)}
 
-	betStatusSlice := make(BetStatusSlice, len(inputs))
-	for index, input := range inputs {
-		betStatusSlice[index] = &BetStatus{
+	betStatusSlice := make(BetStatusSlice, 0, len(inputs))
+	var deadOnly []*BetStatus // 没弃牌但是只下了死钱的座位，比如下完前注就全下了
+	for _, input := range inputs {
+		if input.BetAmount() <= 0 {
+			if input.WinVal() != 0 {
+				deadOnly = append(deadOnly, &BetStatus{seatID: input.SeatID(), winVal: input.WinVal()})
+			}
+			continue
+		}
+		betStatusSlice = append(betStatusSlice, &BetStatus{
 			seatID:    input.SeatID(),
 			winVal:    input.WinVal(),
 			betAmount: input.BetAmount(),
+		})
+	}
+	res.Uncalled = _returnUncalledBet(betStatusSlice)
+	if res.Uncalled != nil {
+		res.Winnings[res.Uncalled.SeatID] += res.Uncalled.Amount
+	}
+	sort.Sort(betStatusSlice)
+
+	// 所有人都弃牌（或者调用方没有填 WinVal）时，当作都没弃牌
+	allFolded := true
+	for _, b := range betStatusSlice {
+		if b.winVal != 0 {
+			allFolded = false
 		}
 	}
+	live := func(b *BetStatus) bool {
+		return allFolded || b.winVal != 0
+	}
 
-	sort.Sort(betStatusSlice)
+	var levels []int64
+	for _, b := range betStatusSlice {
+		if live(b) {
+			levels = append(levels, b.betAmount)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
 
-	winners := make([]int32, 0, len(betStatusSlice))
-	for {
-		// 删除 BetAmount=0 的项 并找出最小的 BetAmount
-		cursor := 0
-		for i := 0; i < len(betStatusSlice); i++ {
-			eachStatus := betStatusSlice[i]
-			if eachStatus.betAmount != 0 {
-				betStatusSlice[cursor] = eachStatus
-				cursor++
+	prev := int64(0)
+	for _, level := range levels {
+		if level == prev {
+			continue
+		}
+		// 最高一层可能有好几个没弃牌的座位，要和最大的下注额比
+		last := level == levels[len(levels)-1]
+		pot := &Pot{}
+		bestWinVal := uint32(0)
+		for _, b := range betStatusSlice {
+			top := level
+			if last && !live(b) {
+				top = b.betAmount
+			}
+			if b.betAmount > prev {
+				pot.Amount += _min64(b.betAmount, top) - prev
+				pot.contributors++
+			}
+			if live(b) && b.betAmount >= level {
+				pot.Eligible = append(pot.Eligible, b.seatID)
+				if len(pot.Eligible) == 1 {
+					bestWinVal = b.winVal
+				}
+				if b.winVal == bestWinVal {
+					pot.Winners = append(pot.Winners, b.seatID)
+				}
 			}
 		}
-		betStatusSlice = betStatusSlice[:cursor]
+		prev = level
 
-		if len(betStatusSlice) == 0 {
-			break
+		sort.Slice(pot.Eligible, func(i, j int) bool { return pot.Eligible[i] < pot.Eligible[j] })
+		opt.sortOddChipWinners(pot.Winners)
+		res.Pots = append(res.Pots, pot)
+	}
+	// 死钱不属于任何人的下注，不会被退还，也不会让下的人多一层资格
+	// 有只下了死钱的座位或者没有别的底池时，死钱单独作为主池，所有没弃牌的座位都有资格赢
+	if opt.DeadMoney > 0 {
+		if len(res.Pots) > 0 && len(deadOnly) == 0 {
+			res.Pots[0].Dead = opt.DeadMoney
+			res.Pots[0].Amount += opt.DeadMoney
+		} else {
+			var candidates []*BetStatus
+			for _, b := range betStatusSlice {
+				if live(b) {
+					candidates = append(candidates, b)
+				}
+			}
+			candidates = append(candidates, deadOnly...)
+			if len(candidates) == 0 {
+				// 没有人下注也都弃牌了，当作都没弃牌
+				for _, input := range inputs {
+					candidates = append(candidates, &BetStatus{seatID: input.SeatID(), winVal: input.WinVal()})
+				}
+			}
+			pot := opt.deadPot(candidates)
+			res.Pots = append([]*Pot{pot}, res.Pots...)
 		}
+	}
 
-		if len(betStatusSlice) == 1 {
-			seatID2Win[betStatusSlice[0].seatID] += betStatusSlice[0].betAmount
-			break
-		}
+	opt.takeRake(res)
+	for _, pot := range res.Pots {
+		res.payPot(pot)
+	}
+	return res
+}
 
-		firstWinner := betStatusSlice[0]
-		firstWinnerBetAmount := firstWinner.BetAmount()
-		pond := int64(0)
-		winners := winners[:0]
+// deadPot 只有死钱的主池，candidates 里牌力最大的赢
+func (opt *PondOptions) deadPot(candidates []*BetStatus) *Pot {
+	pot := &Pot{
+		Amount:       opt.DeadMoney,
+		Dead:         opt.DeadMoney,
+		contributors: len(candidates),
+	}
+	best := uint32(0)
+	for _, b := range candidates {
+		if b.winVal > best {
+			best = b.winVal
+		}
+	}
+	for _, b := range candidates {
+		pot.Eligible = append(pot.Eligible, b.seatID)
+		if b.winVal == best {
+			pot.Winners = append(pot.Winners, b.seatID)
+		}
+	}
+	sort.Slice(pot.Eligible, func(i, j int) bool { return pot.Eligible[i] < pot.Eligible[j] })
+	opt.sortOddChipWinners(pot.Winners)
+	return pot
+}
 
-		for i := 0; i < len(betStatusSlice); i++ {
-			eachStatus := betStatusSlice[i]
-			if eachStatus.winVal == firstWinner.winVal {
-				winners = append(winners, eachStatus.seatID)
+// _returnUncalledBet 把下注最多的人超出第二多的部分从他的下注里扣掉
+func _returnUncalledBet(betStatusSlice BetStatusSlice) *UncalledBet {
+	var top *BetStatus
+	second := int64(0)
+	for _, b := range betStatusSlice {
+		if top == nil || b.betAmount > top.betAmount {
+			if top != nil {
+				second = top.betAmount
 			}
+			top = b
+		} else if b.betAmount > second {
+			second = b.betAmount
+		}
+	}
+	if top == nil || top.betAmount == second {
+		return nil
+	}
 
-			if eachStatus.BetAmount() >= firstWinnerBetAmount {
-				eachStatus.betAmount -= firstWinnerBetAmount
-				pond += firstWinnerBetAmount
-			} else {
-				pond += eachStatus.betAmount
-				eachStatus.betAmount = 0
-			}
+	uncalled := &UncalledBet{SeatID: top.seatID, Amount: top.betAmount - second}
+	top.betAmount = second
+	return uncalled
+}
+
+// takeRake 按抽水规则从各个底池里抽水和奖池，只有一个人下注的底池不抽
+func (opt *PondOptions) takeRake(res *PondResult) {
+	policy := opt.Rake
+	if policy == nil || (policy.NoFlopNoDrop && !opt.FlopSeen) {
+		return
+	}
+
+	total := int64(0)
+	for _, pot := range res.Pots {
+		if pot.contributors > 1 {
+			total += pot.Amount
+		}
+	}
+	for _, pot := range res.Pots {
+		if pot.contributors <= 1 {
+			continue
+		}
+		pot.Rake = pot.Amount * policy.Rate / RAKE_RATE_BASE
+		if policy.Cap > 0 && res.Rake+pot.Rake > policy.Cap {
+			pot.Rake = policy.Cap - res.Rake
+		}
+		res.Rake += pot.Rake
+
+		if policy.JackpotDrop > 0 && res.Jackpot == 0 && total >= policy.JackpotMinPot {
+			pot.Jackpot = _min64(policy.JackpotDrop, pot.Amount-pot.Rake)
+			res.Jackpot += pot.Jackpot
 		}
+	}
+}
 
-		pond /= int64(len(winners))
-		for _, wid := range winners {
-			seatID2Win[wid] += pond
+// payPot 把底池扣掉抽水后平分给赢家，零头按 Winners 的顺序每人一个
+func (res *PondResult) payPot(pot *Pot) {
+	if len(pot.Winners) == 0 {
+		res.Unclaimed += pot.Amount - pot.Rake - pot.Jackpot
+		return
+	}
+	amount := pot.Amount - pot.Rake - pot.Jackpot
+	pot.Share = amount / int64(len(pot.Winners))
+	for _, wid := range pot.Winners {
+		res.Winnings[wid] += pot.Share
+	}
+	odd := amount - pot.Share*int64(len(pot.Winners))
+	for _, wid := range pot.Winners[:odd] {
+		res.Winnings[wid]++
+		pot.OddChips = append(pot.OddChips, OddChip{SeatID: wid, Amount: 1})
+	}
+}
+
+func _min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func _max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// sortOddChipWinners 按零头规则给赢家排序，排在前面的先拿零头
+func (opt *PondOptions) sortOddChipWinners(winners []int32) {
+	switch opt.OddChip {
+	case OddChipLeftOfButton:
+		// 座位号比庄家大的排前面，其它的绕一圈排到后面
+		distance := func(seatID int32) int64 {
+			d := int64(seatID) - int64(opt.ButtonSeat)
+			if d <= 0 {
+				d += 1 << 32
+			}
+			return d
 		}
+		sort.Slice(winners, func(i, j int) bool {
+			return distance(winners[i]) < distance(winners[j])
+		})
+	case OddChipHighestSuit:
+		sort.Slice(winners, func(i, j int) bool {
+			ci, cj := opt.highestCard(winners[i]), opt.highestCard(winners[j])
+			if ci != cj {
+				return ci > cj
+			}
+			return winners[i] < winners[j]
+		})
+	default:
+		sort.Slice(winners, func(i, j int) bool {
+			return winners[i] < winners[j]
+		})
 	}
+}
 
-	return seatID2Win
+// oddChipSuitOrder 花色的大小 ♣ < ♦ < ♥ < ♠，下标是 poker.Card 的 Suit()
+var oddChipSuitOrder = []uint32{0, 2, 1, 3, 4}
+
+// highestCard 座位手牌里最大的一张牌的排序值，没有手牌时为 0
+func (opt *PondOptions) highestCard(seatID int32) uint32 {
+	best := uint32(0)
+	for _, c := range opt.HoleCards[seatID] {
+		value, suit := c.Value(), c.Suit()
+		if value == 1 {
+			value = ACE_VALUE
+		}
+		if int(suit) >= len(oddChipSuitOrder) {
+			continue
+		}
+		if v := value<<4 | oddChipSuitOrder[suit]; v > best {
+			best = v
+		}
+	}
+	return best
 }
 
 type BetStatus struct {
@@ -0,0 +1,233 @@
+package texas_holdem
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func sumWin(win SeatID2WinAmount) int64 {
+	sum := int64(0)
+	for _, v := range win {
+		sum += v
+	}
+	return sum
+}
+
+func TestDistributePondOddChip(t *testing.T) {
+	// 底池 100 三人平分，每人 33 还剩 1 个零头
+	inputs := []IBetStatus{
+		NewBetStatus(1, 5, 25),
+		NewBetStatus(3, 5, 25),
+		NewBetStatus(5, 5, 25),
+		NewBetStatus(7, 0, 25),
+	}
+
+	cases := []struct {
+		opt  *PondOptions
+		seat int32
+	}{
+		{nil, 1},
+		{&PondOptions{OddChip: OddChipLeftOfButton, ButtonSeat: 3}, 5},
+		{&PondOptions{OddChip: OddChipLeftOfButton, ButtonSeat: 5}, 1},
+		{&PondOptions{OddChip: OddChipHighestSuit, HoleCards: map[int32][]poker.Card{
+			1: poker.String2pokers("131, 22"), // K♦
+			3: poker.String2pokers("134, 32"), // K♠
+			5: poker.String2pokers("122, 92"), // Q♣
+		}}, 3},
+	}
+	for _, c := range cases {
+		win, oddChips := DistributePondWithOptions(inputs, c.opt)
+		if sumWin(win) != 100 {
+			t.Error("chips lost", win)
+		}
+		if len(oddChips) != 1 || oddChips[0].SeatID != c.seat || win[c.seat] != 34 {
+			t.Error("err odd chip", c.seat, oddChips, win)
+		}
+	}
+}
+
+func TestDistributePondConserveChips(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		seats := r.Intn(9) + 2
+		inputs := make([]IBetStatus, seats)
+		total := int64(0)
+		for i := range inputs {
+			bet := r.Int63n(1000)
+			total += bet
+			inputs[i] = NewBetStatus(int32(i), uint32(r.Intn(4)), bet)
+		}
+		if win := DistributePond(inputs); sumWin(win) != total {
+			t.Fatal("chips lost", total, win)
+		}
+	}
+}
+
+func TestDistributePondConserveDeadMoney(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for n := 0; n < 1000; n++ {
+		// 座位数可以是 0，下注可以是 0，死钱总要有去处
+		inputs := make([]IBetStatus, r.Intn(6))
+		total := r.Int63n(100)
+		for i := range inputs {
+			bet := r.Int63n(3) * r.Int63n(500)
+			total += bet
+			inputs[i] = NewBetStatus(int32(i), uint32(r.Intn(3)), bet)
+		}
+		dead := total - func() int64 {
+			sum := int64(0)
+			for _, in := range inputs {
+				sum += in.BetAmount()
+			}
+			return sum
+		}()
+		res := DistributePondDetail(inputs, &PondOptions{DeadMoney: dead})
+		if sumWin(res.Winnings)+res.Rake+res.Jackpot+res.Unclaimed != total {
+			t.Fatal("chips lost", total, res.Winnings, res.Unclaimed)
+		}
+		if len(inputs) > 0 && res.Unclaimed != 0 {
+			t.Fatal("dead money should be won by a seat", res.Unclaimed)
+		}
+	}
+
+	res := DistributePondDetail(nil, &PondOptions{DeadMoney: 15})
+	if res.Unclaimed != 15 || len(res.Pots) != 1 || res.Pots[0].Dead != 15 {
+		t.Error("dead money without seats should be unclaimed", res.Unclaimed, res.Pots)
+	}
+}
+
+func TestDistributePondTiedTopLevel(t *testing.T) {
+	// 两个没弃牌的座位下注一样多，弃牌座位超过这一层的部分也要进底池
+	inputs := []IBetStatus{
+		NewBetStatus(1, 0, 150),
+		NewBetStatus(2, 0, 150),
+		NewBetStatus(3, 5, 100),
+		NewBetStatus(4, 3, 100),
+	}
+	res := DistributePondDetail(inputs, nil)
+	if sumWin(res.Winnings) != 500 || res.Winnings[3] != 500 {
+		t.Fatal("chips lost", res.Winnings)
+	}
+	if len(res.Pots) != 1 || res.Pots[0].Amount != 500 {
+		t.Error("err pots", res.Pots)
+	}
+
+	// 随机的下注额很难碰到这种情况，这里让没弃牌的座位下注一样多
+	r := rand.New(rand.NewSource(2))
+	for n := 0; n < 1000; n++ {
+		seats := r.Intn(8) + 3
+		live := r.Int63n(500) + 1
+		inputs := make([]IBetStatus, seats)
+		total := int64(0)
+		for i := range inputs {
+			bet, winVal := live, uint32(r.Intn(3)+1)
+			if r.Intn(2) == 0 {
+				bet, winVal = r.Int63n(1000), 0
+			}
+			total += bet
+			inputs[i] = NewBetStatus(int32(i), winVal, bet)
+		}
+		if win := DistributePond(inputs); sumWin(win) != total {
+			t.Fatal("chips lost", total, win)
+		}
+	}
+}
+
+func TestDistributePondDeadMoneyOnly(t *testing.T) {
+	// 没有人下注时死钱也不能丢
+	inputs := []IBetStatus{NewBetStatus(1, 3, 0), NewBetStatus(2, 5, 0), NewBetStatus(3, 0, 0)}
+	res := DistributePondDetail(inputs, &PondOptions{DeadMoney: 15})
+	if len(res.Pots) != 1 || res.Pots[0].Dead != 15 || res.Winnings[2] != 15 || sumWin(res.Winnings) != 15 {
+		t.Fatal("err dead money", res.Pots, res.Winnings)
+	}
+	if !reflect.DeepEqual(res.Pots[0].Eligible, []int32{1, 2}) {
+		t.Error("err eligible", res.Pots[0].Eligible)
+	}
+}
+
+func TestDistributePondDetail(t *testing.T) {
+	inputs := []IBetStatus{
+		NewBetStatus(1, 8, 300),
+		NewBetStatus(2, 9, 100), // 全下 100，牌最大
+		NewBetStatus(3, 7, 300),
+		NewBetStatus(4, 0, 50), // 弃牌
+	}
+	res := DistributePondDetail(inputs, nil)
+	if len(res.Pots) != 2 {
+		t.Fatal("err pots", res.Pots)
+	}
+	main, side := res.Pots[0], res.Pots[1]
+	if main.Amount != 350 || !reflect.DeepEqual(main.Eligible, []int32{1, 2, 3}) ||
+		!reflect.DeepEqual(main.Winners, []int32{2}) || main.Share != 350 {
+		t.Error("err main pot", main)
+	}
+	if side.Amount != 400 || !reflect.DeepEqual(side.Eligible, []int32{1, 3}) ||
+		!reflect.DeepEqual(side.Winners, []int32{1}) || side.Share != 400 {
+		t.Error("err side pot", side)
+	}
+	if !reflect.DeepEqual(res.Winnings, DistributePond(inputs)) || res.Winnings[2] != 350 || res.Winnings[1] != 400 {
+		t.Error("err winnings", res.Winnings)
+	}
+}
+
+func TestDistributePondRake(t *testing.T) {
+	inputs := []IBetStatus{
+		NewBetStatus(1, 8, 300),
+		NewBetStatus(2, 9, 100),
+		NewBetStatus(3, 7, 300),
+	}
+	policy := &RakePolicy{Rate: 500, Cap: 30, JackpotDrop: 10, JackpotMinPot: 500}
+
+	res := DistributePondDetail(inputs, &PondOptions{Rake: policy, FlopSeen: true})
+	main, side := res.Pots[0], res.Pots[1]
+	// 主池 300 抽 15 和奖池 10，边池 400 抽 20 但封顶只剩 15
+	if main.Rake != 15 || main.Jackpot != 10 || side.Rake != 15 || side.Jackpot != 0 {
+		t.Error("err rake", main, side)
+	}
+	if res.Rake != 30 || res.Jackpot != 10 || res.Winnings[2] != 275 || res.Winnings[1] != 385 {
+		t.Error("err winnings", res.Rake, res.Jackpot, res.Winnings)
+	}
+	if sumWin(res.Winnings)+res.Rake+res.Jackpot != 700 {
+		t.Error("chips lost", res)
+	}
+
+	res = DistributePondDetail(inputs, &PondOptions{Rake: &RakePolicy{Rate: 500, NoFlopNoDrop: true}})
+	if res.Rake != 0 || sumWin(res.Winnings) != 700 {
+		t.Error("no flop no drop", res.Rake, res.Winnings)
+	}
+}
+
+func TestDistributePondUncalledBet(t *testing.T) {
+	// 2 号下注 500 后弃牌，其他人最多只下了 200，多出来的 300 退给他
+	inputs := []IBetStatus{
+		NewBetStatus(1, 8, 200),
+		NewBetStatus(2, 0, 500),
+		NewBetStatus(3, 9, 100),
+	}
+	res := DistributePondDetail(inputs, &PondOptions{Rake: &RakePolicy{Rate: 1000}})
+	if res.Uncalled == nil || res.Uncalled.SeatID != 2 || res.Uncalled.Amount != 300 {
+		t.Fatal("err uncalled bet", res.Uncalled)
+	}
+	for _, pot := range res.Pots {
+		for _, seat := range pot.Eligible {
+			if seat == 2 {
+				t.Error("folded seat should not be eligible", pot)
+			}
+		}
+	}
+	// 主池 300 抽 30 给 3 号，边池 200 抽 20 给 1 号
+	if res.Winnings[2] != 300 || res.Winnings[3] != 270 || res.Winnings[1] != 180 {
+		t.Error("err winnings", res.Winnings)
+	}
+
+	res = DistributePondDetail([]IBetStatus{
+		NewBetStatus(1, 8, 200),
+		NewBetStatus(2, 9, 200),
+	}, nil)
+	if res.Uncalled != nil {
+		t.Error("called bet should not be returned", res.Uncalled)
+	}
+}
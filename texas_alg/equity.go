@@ -0,0 +1,282 @@
+package texas_holdem
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+const (
+	MAX_SEATS      = 10 // 一桌最多的座位数
+	HOLE_CARD_SIZE = 2  // 手牌张数
+	BOARD_SIZE     = 5  // 公共牌张数
+)
+
+// SeatEquity 一个座位的胜率统计
+type SeatEquity struct {
+	Win    float64 // 独赢的概率
+	Tie    float64 // 和别人平分的概率
+	Lose   float64 // 输的概率
+	Equity float64 // 期望分到的底池比例，平分时按份额计算（两人平分算 1/2）
+}
+
+func (e SeatEquity) String() string {
+	return fmt.Sprintf("win %.4f tie %.4f lose %.4f equity %.4f", e.Win, e.Tie, e.Lose, e.Equity)
+}
+
+// EquityAnalyze 多人胜率分析，穷举所有未知的公共牌和对手手牌
+// known 是已知手牌的座位（一般 known[0] 是自己），randomOpponents 是手牌未知的对手个数
+// 返回值按座位排列：先是 known 里的座位，然后是 randomOpponents 个随机对手
+func EquityAnalyze(known [][]poker.Card, public []poker.Card, randomOpponents int) ([]SeatEquity, error) {
+	cardShowed, err := _checkEquityInput(known, public, randomOpponents)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := len(known) + randomOpponents
+	counter := newEquityCounter(seats)
+	e := newEquityEnumerator(known, public, seats, cardShowed)
+
+	_forEachCombination(len(e.rest), BOARD_SIZE-len(public), func(idx []int) {
+		for i, j := range idx {
+			e.board[len(public)+i] = e.rest[j]
+			e.used[j] = true
+		}
+		for i, hole := range known {
+			_evalHand(e.hands[i], e.buf, hole, e.board)
+		}
+		e.enumerateOpponents(len(known), counter)
+		for _, j := range idx {
+			e.used[j] = false
+		}
+	})
+
+	return counter.result(), nil
+}
+
+func _checkEquityInput(known [][]poker.Card, public []poker.Card, randomOpponents int) (map[poker.Card]bool, error) {
+	if randomOpponents < 0 {
+		return nil, errors.New("random opponents must not be negative")
+	}
+	seats := len(known) + randomOpponents
+	if seats < 2 || seats > MAX_SEATS {
+		return nil, fmt.Errorf("seat count %d not supported", seats)
+	}
+	if len(public) > BOARD_SIZE {
+		return nil, fmt.Errorf("too many public cards: %d", len(public))
+	}
+
+	cardShowed := make(map[poker.Card]bool)
+	mark := func(c poker.Card) error {
+		c = _normalizeCard(c)
+		if !_isDeckCard(c) {
+			return fmt.Errorf("invalid card %d", uint32(c))
+		}
+		if cardShowed[c] {
+			return fmt.Errorf("duplicate card %v", c)
+		}
+		cardShowed[c] = true
+		return nil
+	}
+	for i, hole := range known {
+		if len(hole) != HOLE_CARD_SIZE {
+			return nil, fmt.Errorf("seat %d has %d hole cards", i, len(hole))
+		}
+		for _, c := range hole {
+			if err := mark(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, c := range public {
+		if err := mark(c); err != nil {
+			return nil, err
+		}
+	}
+
+	need := BOARD_SIZE - len(public) + randomOpponents*HOLE_CARD_SIZE
+	if need > len(poker.Deck)-len(cardShowed) {
+		return nil, errors.New("not enough cards left in deck")
+	}
+	return cardShowed, nil
+}
+
+func _isDeckCard(c poker.Card) bool {
+	return c.Value() >= 1 && c.Value() <= 13 && c.Suit() >= 1 && c.Suit() <= SUIT_SIZE
+}
+
+// _normalizeCard A 也可能用 ACE_VALUE 表示，统一成 poker.Deck 里的 1
+func _normalizeCard(c poker.Card) poker.Card {
+	if c.Value() == ACE_VALUE {
+		return poker.MakeCard(1, c.Suit())
+	}
+	return c
+}
+
+// _restDeck 剩下没出现过的牌，顺序与 poker.Deck 一致
+func _restDeck(cardShowed map[poker.Card]bool) []poker.Card {
+	rest := make([]poker.Card, 0, len(poker.Deck))
+	for _, c := range poker.Deck {
+		if !cardShowed[c] {
+			rest = append(rest, c)
+		}
+	}
+	return rest
+}
+
+func _newHands(n int) []*Hand {
+	hands := make([]*Hand, n)
+	for i := range hands {
+		hands[i] = NewHand()
+		hands[i].SetNeedCalIndex(false)
+	}
+	return hands
+}
+
+// _evalHand 用 hole + board 组成 7 张牌计算牌力，buf 用来避免重复分配
+func _evalHand(h *Hand, buf, hole, board []poker.Card) {
+	buf = append(buf[:0], hole...)
+	buf = append(buf, board...)
+	h.SetCard(buf)
+}
+
+// _forEachCombination 按字典序枚举 n 个元素里取 k 个的所有下标组合
+func _forEachCombination(n, k int, fn func(idx []int)) {
+	idx := make([]int, k)
+	var walk func(pos, start int)
+	walk = func(pos, start int) {
+		if pos == k {
+			fn(idx)
+			return
+		}
+		for i := start; i <= n-(k-pos); i++ {
+			idx[pos] = i
+			walk(pos+1, i+1)
+		}
+	}
+	walk(0, 0)
+}
+
+type equityEnumerator struct {
+	rest  []poker.Card
+	used  []bool
+	board []poker.Card
+	buf   []poker.Card
+	hole  []poker.Card
+	hands []*Hand
+}
+
+func newEquityEnumerator(known [][]poker.Card, public []poker.Card, seats int, cardShowed map[poker.Card]bool) *equityEnumerator {
+	rest := _restDeck(cardShowed)
+	e := &equityEnumerator{
+		rest:  rest,
+		used:  make([]bool, len(rest)),
+		board: make([]poker.Card, BOARD_SIZE),
+		buf:   make([]poker.Card, 0, HOLE_CARD_SIZE+BOARD_SIZE),
+		hole:  make([]poker.Card, HOLE_CARD_SIZE),
+		hands: _newHands(seats),
+	}
+	copy(e.board, public)
+	return e
+}
+
+// enumerateOpponents 从 seat 开始给每个随机对手枚举所有可能的两张手牌
+func (e *equityEnumerator) enumerateOpponents(seat int, counter *equityCounter) {
+	if seat == len(e.hands) {
+		counter.add(e.hands)
+		return
+	}
+	for j := 0; j < len(e.rest); j++ {
+		if e.used[j] {
+			continue
+		}
+		e.used[j] = true
+		for k := j + 1; k < len(e.rest); k++ {
+			if e.used[k] {
+				continue
+			}
+			e.used[k] = true
+			e.hole[0], e.hole[1] = e.rest[j], e.rest[k]
+			_evalHand(e.hands[seat], e.buf, e.hole, e.board)
+			e.enumerateOpponents(seat+1, counter)
+			e.used[k] = false
+		}
+		e.used[j] = false
+	}
+}
+
+// equityCounter 累计每个座位的输赢次数，每次摊牌可以带权重
+type equityCounter struct {
+	win   []float64
+	tie   []float64
+	lose  []float64
+	share []float64
+	sq    []float64 // 每次分到份额的平方和，用来算方差
+	total float64
+	n     int64 // 记录了多少次摊牌
+}
+
+func newEquityCounter(seats int) *equityCounter {
+	return &equityCounter{
+		win:   make([]float64, seats),
+		tie:   make([]float64, seats),
+		lose:  make([]float64, seats),
+		share: make([]float64, seats),
+		sq:    make([]float64, seats),
+	}
+}
+
+// add 记录一次摊牌结果，hands 是每个座位算好牌力的手牌，用 Hand.Win 和 Hand.Tie 比大小
+func (c *equityCounter) add(hands []*Hand) {
+	c.addWeighted(hands, 1)
+}
+
+func (c *equityCounter) addWeighted(hands []*Hand, weight float64) {
+	best := hands[0]
+	for _, h := range hands[1:] {
+		if h.Win(best) {
+			best = h
+		}
+	}
+	bestCount := 0
+	for _, h := range hands {
+		if h.Tie(best) {
+			bestCount++
+		}
+	}
+
+	share := 1 / float64(bestCount)
+	for i, h := range hands {
+		switch {
+		case best.Win(h):
+			c.lose[i] += weight
+		case bestCount == 1:
+			c.win[i] += weight
+			c.share[i] += weight
+			c.sq[i] += weight
+		default:
+			c.tie[i] += weight
+			c.share[i] += share * weight
+			c.sq[i] += share * share * weight
+		}
+	}
+	c.total += weight
+	c.n++
+}
+
+func (c *equityCounter) result() []SeatEquity {
+	res := make([]SeatEquity, len(c.win))
+	if c.total == 0 {
+		return res
+	}
+	for i := range res {
+		res[i] = SeatEquity{
+			Win:    c.win[i] / c.total,
+			Tie:    c.tie[i] / c.total,
+			Lose:   c.lose[i] / c.total,
+			Equity: c.share[i] / c.total,
+		}
+	}
+	return res
+}
@@ -0,0 +1,167 @@
+package texas_holdem
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+const (
+	DEFAULT_MONTE_CARLO_SAMPLES = 100000
+	CONFIDENCE_Z_95             = 1.959964 // 95% 置信区间对应的正态分位数
+
+	timeCheckInterval = 256 // 每采样多少次检查一次时间
+)
+
+// MonteCarloOptions 蒙特卡洛采样参数
+// Samples 和 Duration 至少设置一个，两个都设置时先到达的那个为准；都不设置时采样 DEFAULT_MONTE_CARLO_SAMPLES 次
+// Rand 为 nil 时用当前时间做种子，需要复现结果时传入固定种子的 rand.Rand
+type MonteCarloOptions struct {
+	Samples  int
+	Duration time.Duration
+	Rand     *rand.Rand
+}
+
+// EquityEstimate 采样估算出来的胜率
+type EquityEstimate struct {
+	SeatEquity
+	Samples int     // 实际采样次数
+	StdErr  float64 // Equity 的标准误差
+	Low     float64 // Equity 95% 置信区间的下限
+	High    float64 // Equity 95% 置信区间的上限
+}
+
+// EquityMonteCarlo 随机采样未知的公共牌和对手手牌来估算胜率，参数和返回值的座位顺序同 EquityAnalyze
+func EquityMonteCarlo(known [][]poker.Card, public []poker.Card, randomOpponents int, opt MonteCarloOptions) ([]EquityEstimate, error) {
+	cardShowed, err := _checkEquityInput(known, public, randomOpponents)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := len(known) + randomOpponents
+	counter := newEquityCounter(seats)
+	e := newEquityEnumerator(known, public, seats, cardShowed)
+	r := opt.random()
+	boardMissing := BOARD_SIZE - len(public)
+	draw := boardMissing + randomOpponents*HOLE_CARD_SIZE
+
+	opt.run(func() {
+		_partialShuffle(r, e.rest, draw)
+		copy(e.board[len(public):], e.rest[:boardMissing])
+		for i, hole := range known {
+			_evalHand(e.hands[i], e.buf, hole, e.board)
+		}
+		for i := len(known); i < seats; i++ {
+			offset := boardMissing + (i-len(known))*HOLE_CARD_SIZE
+			_evalHand(e.hands[i], e.buf, e.rest[offset:offset+HOLE_CARD_SIZE], e.board)
+		}
+		counter.add(e.hands)
+	})
+
+	return counter.estimate(), nil
+}
+
+func (opt MonteCarloOptions) random() *rand.Rand {
+	if opt.Rand != nil {
+		return opt.Rand
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// run 按采样次数或者时间预算重复执行 sample
+func (opt MonteCarloOptions) run(sample func()) {
+	samples := opt.Samples
+	if samples <= 0 && opt.Duration <= 0 {
+		samples = DEFAULT_MONTE_CARLO_SAMPLES
+	}
+	var deadline time.Time
+	if opt.Duration > 0 {
+		deadline = time.Now().Add(opt.Duration)
+	}
+
+	for n := 0; samples <= 0 || n < samples; n++ {
+		if opt.Duration > 0 && n%timeCheckInterval == 0 && n > 0 && time.Now().After(deadline) {
+			return
+		}
+		sample()
+	}
+}
+
+// _partialShuffle Fisher-Yates 洗牌，只把前 n 张洗成随机的
+func _partialShuffle(r *rand.Rand, cards []poker.Card, n int) {
+	for i := 0; i < n; i++ {
+		j := r.Intn(len(cards)-i) + i
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+}
+
+// estimate 把累计的结果转成带标准误差和置信区间的估算值
+func (c *equityCounter) estimate() []EquityEstimate {
+	eq := c.result()
+	res := make([]EquityEstimate, len(eq))
+	for i := range res {
+		res[i].SeatEquity = eq[i]
+		res[i].Samples = int(c.n)
+		if c.n > 1 {
+			n := float64(c.n)
+			variance := (c.sq[i]/n - eq[i].Equity*eq[i].Equity) * n / (n - 1)
+			res[i].StdErr = math.Sqrt(math.Max(variance, 0) / n)
+		}
+		res[i].Low = math.Max(eq[i].Equity-CONFIDENCE_Z_95*res[i].StdErr, 0)
+		res[i].High = math.Min(eq[i].Equity+CONFIDENCE_Z_95*res[i].StdErr, 1)
+	}
+	return res
+}
+
+// EXACT_ENUMERATION_LIMIT EquityCalculate 穷举的发牌组合数上限，超过就改用采样
+const EXACT_ENUMERATION_LIMIT = 2000000
+
+// EquityCalculate 计算胜率，公共牌可以是 0~5 张
+// 需要枚举的发牌组合不超过 EXACT_ENUMERATION_LIMIT 时穷举（结果精确，StdErr 为 0），否则按 opt 采样
+func EquityCalculate(known [][]poker.Card, public []poker.Card, randomOpponents int, opt MonteCarloOptions) ([]EquityEstimate, error) {
+	cardShowed, err := _checkEquityInput(known, public, randomOpponents)
+	if err != nil {
+		return nil, err
+	}
+
+	deals := _countDeals(len(poker.Deck)-len(cardShowed), BOARD_SIZE-len(public), randomOpponents)
+	if deals > EXACT_ENUMERATION_LIMIT {
+		return EquityMonteCarlo(known, public, randomOpponents, opt)
+	}
+
+	exact, err := EquityAnalyze(known, public, randomOpponents)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]EquityEstimate, len(exact))
+	for i, eq := range exact {
+		res[i] = EquityEstimate{
+			SeatEquity: eq,
+			Samples:    int(deals),
+			Low:        eq.Equity,
+			High:       eq.Equity,
+		}
+	}
+	return res, nil
+}
+
+// _countDeals 剩 rest 张牌时，补齐 boardMissing 张公共牌再给 randomOpponents 个对手各发两张，一共有多少种发法
+func _countDeals(rest, boardMissing, randomOpponents int) float64 {
+	deals := _combination(rest, boardMissing)
+	rest -= boardMissing
+	for i := 0; i < randomOpponents; i++ {
+		deals *= _combination(rest, HOLE_CARD_SIZE)
+		rest -= HOLE_CARD_SIZE
+	}
+	return deals
+}
+
+func _combination(n, k int) float64 {
+	res := 1.0
+	for i := 0; i < k; i++ {
+		res = res * float64(n-i) / float64(i+1)
+	}
+	return res
+}
@@ -0,0 +1,195 @@
+package texas_holdem
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func floatEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEquityAnalyzeKnown(t *testing.T) {
+	aa := poker.String2pokers("14, 13")
+	kk := poker.String2pokers("134, 133")
+	public := poker.String2pokers("21, 32, 73, 94, 102")
+
+	res, err := EquityAnalyze([][]poker.Card{aa, kk}, public, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Win != 1 || res[1].Lose != 1 {
+		t.Error("AA should win", res)
+	}
+}
+
+func TestEquityAnalyzeTie(t *testing.T) {
+	public := poker.String2pokers("11, 131, 121, 111, 101")
+	known := [][]poker.Card{
+		poker.String2pokers("14, 13"),
+		poker.String2pokers("134, 133"),
+		poker.String2pokers("22, 23"),
+	}
+
+	res, err := EquityAnalyze(known, public, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range res {
+		if r.Tie != 1 || !floatEqual(r.Equity, 1.0/3) {
+			t.Error("seat", i, "should split the pot", r)
+		}
+	}
+}
+
+func TestEquityAnalyzeRandomOpponent(t *testing.T) {
+	known := [][]poker.Card{poker.String2pokers("14, 13")}
+	public := poker.String2pokers("21, 32, 73, 94")
+
+	tick := time.Now()
+	res, err := EquityAnalyze(known, public, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("equity", res, "use time", time.Since(tick))
+
+	sum := 0.0
+	for _, r := range res {
+		if !floatEqual(r.Win+r.Tie+r.Lose, 1) {
+			t.Error("win + tie + lose should be 1", r)
+		}
+		sum += r.Equity
+	}
+	if !floatEqual(sum, 1) {
+		t.Error("equity should sum to 1", sum)
+	}
+	if res[0].Equity < 0.8 {
+		t.Error("AA equity too low", res[0])
+	}
+}
+
+func TestEquityAnalyzeBadInput(t *testing.T) {
+	aa := poker.String2pokers("14, 13")
+	if _, err := EquityAnalyze([][]poker.Card{aa, aa}, nil, 0); err == nil {
+		t.Error("duplicate cards should fail")
+	}
+	if _, err := EquityAnalyze([][]poker.Card{aa}, nil, 0); err == nil {
+		t.Error("one seat should fail")
+	}
+	if _, err := EquityAnalyze([][]poker.Card{aa[:1]}, nil, 1); err == nil {
+		t.Error("one hole card should fail")
+	}
+}
+
+func TestEquityMonteCarloAgreesWithExact(t *testing.T) {
+	known := [][]poker.Card{poker.String2pokers("14, 13")}
+	public := poker.String2pokers("21, 32, 73, 94")
+
+	exact, err := EquityAnalyze(known, public, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := MonteCarloOptions{Samples: 200000, Rand: rand.New(rand.NewSource(1))}
+	estimate, err := EquityMonteCarlo(known, public, 1, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range exact {
+		fmt.Println("exact", exact[i], "estimate", estimate[i].Equity, "stderr", estimate[i].StdErr)
+		if math.Abs(exact[i].Equity-estimate[i].Equity) > 4*estimate[i].StdErr {
+			t.Error("estimate too far from exact", i, exact[i], estimate[i])
+		}
+		if estimate[i].Low > exact[i].Equity+0.01 || estimate[i].High < exact[i].Equity-0.01 {
+			t.Error("confidence interval misses exact equity", estimate[i])
+		}
+	}
+}
+
+func TestEquityMonteCarloSeeded(t *testing.T) {
+	known := [][]poker.Card{poker.String2pokers("14, 13"), poker.String2pokers("134, 133")}
+
+	run := func() []EquityEstimate {
+		opt := MonteCarloOptions{Samples: 5000, Rand: rand.New(rand.NewSource(42))}
+		res, err := EquityMonteCarlo(known, nil, 2, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	a, b := run(), run()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Error("same seed should give same result", a[i], b[i])
+		}
+	}
+	if a[0].Samples != 5000 {
+		t.Error("err samples", a[0].Samples)
+	}
+}
+
+func TestEquityMonteCarloDuration(t *testing.T) {
+	known := [][]poker.Card{poker.String2pokers("14, 13")}
+
+	tick := time.Now()
+	res, err := EquityMonteCarlo(known, nil, 3, MonteCarloOptions{Duration: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(tick) > time.Second || res[0].Samples == 0 {
+		t.Error("duration budget not respected", time.Since(tick), res[0].Samples)
+	}
+}
+
+func TestEquityCalculatePreflop(t *testing.T) {
+	known := [][]poker.Card{poker.String2pokers("14, 13"), poker.String2pokers("134, 133")}
+
+	opt := MonteCarloOptions{Samples: 100000, Rand: rand.New(rand.NewSource(7))}
+	res, err := EquityCalculate(known, nil, 0, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("AA vs KK preflop", res[0].SeatEquity, "stderr", res[0].StdErr)
+	// AA 对 KK 翻牌前大约 82%
+	if math.Abs(res[0].Equity-0.82) > 0.01 {
+		t.Error("err preflop equity", res[0])
+	}
+}
+
+func TestEquityCalculateFlop(t *testing.T) {
+	player := poker.String2pokers("14, 13")
+	public := poker.String2pokers("21, 32, 73")
+
+	exact, err := EquityAnalyze([][]poker.Card{player}, public, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := EquityCalculate([][]poker.Card{player}, public, 1, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].SeatEquity != exact[0] || res[0].StdErr != 0 {
+		t.Error("flop heads up should be enumerated", res[0], exact[0])
+	}
+	rate := WinloseAnalyze(player, public)
+	if math.Abs(float64(rate)-(exact[0].Win+exact[0].Tie)) > 1e-6 {
+		t.Error("err win or tie rate", rate, exact[0])
+	}
+}
+
+func TestWinloseAnalyzeDeterministic(t *testing.T) {
+	player := []poker.Card{poker.AceSpades, poker.AceHearts}
+	// 翻牌前要采样，同样的输入结果也要一样
+	a, b := WinloseAnalyze(player, nil), WinloseAnalyze(player, nil)
+	if a != b || a < 0.8 || a > 0.9 {
+		t.Error("err preflop rate", a, b)
+	}
+	seeded := WinloseAnalyzeWithOptions(player, nil, MonteCarloOptions{Rand: rand.New(rand.NewSource(WINLOSE_ANALYZE_SEED))})
+	if seeded != a {
+		t.Error("same seed should give the same rate", seeded, a)
+	}
+}
@@ -0,0 +1,162 @@
+package texas_holdem
+
+import (
+	"sync"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+/* 查表的牌力计算，结果和 (*Hand).FinalLevel() 完全一样，但是不分配内存
+---------------------------------------------------------------
+把牌值按 2~A 编号成 0~12
+1、有同花的情况（5~7 张牌里同花的那个花色至少 5 张）
+   5~7 张牌里有同花时不可能同时有四条或者葫芦，所以结果只取决于这个花色的牌集（13 位的二进制），
+   用 flushTable[牌集] 直接查到
+2、没有同花的情况
+   结果只取决于每种牌值出现的次数 q[0..12]（每一位 0~4，总和是牌的张数），
+   把 q 看成一个五进制数，所有和为 n 的五进制数按字典序排好，它的序号就是查表的下标（完美哈希），
+   序号用 quinaryDp 累加得到，见 _hashQuinary
+两张表都在第一次使用时用 Hand 计算填好
+*/
+
+const (
+	rankSize     = 13
+	minEvalCards = 5
+	maxEvalCards = 7
+	maxRankCount = 4
+)
+
+var (
+	evalTablesOnce sync.Once
+	flushTable     [1 << rankSize]uint32
+	noFlushTable   [maxEvalCards + 1][]uint32
+
+	// quinaryCount[n][k] 长度为 n，每一位 0~4，总和为 k 的五进制数的个数
+	quinaryCount [rankSize + 1][maxEvalCards + 1]uint32
+	// quinaryDp[d][n][k] 后面还有 n 位、剩余总和为 k 时，这一位取比 d 小的值一共有多少个数排在前面
+	quinaryDp [maxRankCount + 1][rankSize + 1][maxEvalCards + 1]uint32
+)
+
+// EvalCards 查表计算 5~7 张牌的牌力，结果与 SetCard 之后的 (*Hand).FinalLevel() 相同
+// 牌的张数不对时返回 0（HandTypeUnknown）
+func EvalCards(cards []poker.Card) uint32 {
+	if len(cards) < minEvalCards || len(cards) > maxEvalCards {
+		return 0
+	}
+	evalTablesOnce.Do(_initEvalTables)
+
+	var quinary [rankSize]uint8
+	var suitFlag [SUIT_SIZE]uint16
+	var suitCount [SUIT_SIZE]uint8
+	for _, c := range cards {
+		rank := _rankIndex(c)
+		suit := (c.Suit() - 1) & (SUIT_SIZE - 1)
+		quinary[rank]++
+		suitFlag[suit] |= 1 << rank
+		suitCount[suit]++
+	}
+
+	for suit := range suitCount {
+		if suitCount[suit] >= minEvalCards {
+			return flushTable[suitFlag[suit]]
+		}
+	}
+	return noFlushTable[len(cards)][_hashQuinary(&quinary, len(cards))]
+}
+
+// _rankIndex 牌值 2~A 对应 0~12
+func _rankIndex(c poker.Card) uint32 {
+	v := c.Value()
+	if v == 1 || v == ACE_VALUE {
+		return rankSize - 1
+	}
+	return (v - 2) % rankSize
+}
+
+// _hashQuinary 五进制数 q（总和为 k）在所有总和为 k 的五进制数里的字典序序号
+func _hashQuinary(q *[rankSize]uint8, k int) uint32 {
+	sum := uint32(0)
+	for i := 0; i < rankSize && k > 0; i++ {
+		sum += quinaryDp[q[i]][rankSize-i-1][k]
+		k -= int(q[i])
+	}
+	return sum
+}
+
+func _initEvalTables() {
+	quinaryCount[0][0] = 1
+	for n := 1; n <= rankSize; n++ {
+		for k := 0; k <= maxEvalCards; k++ {
+			for d := 0; d <= maxRankCount && d <= k; d++ {
+				quinaryCount[n][k] += quinaryCount[n-1][k-d]
+			}
+		}
+	}
+	for d := 1; d <= maxRankCount; d++ {
+		for n := 0; n <= rankSize; n++ {
+			for k := 0; k <= maxEvalCards; k++ {
+				quinaryDp[d][n][k] = quinaryDp[d-1][n][k]
+				if k >= d-1 {
+					quinaryDp[d][n][k] += quinaryCount[n][k-d+1]
+				}
+			}
+		}
+	}
+
+	h := NewHand()
+	h.SetNeedCalIndex(false)
+	cards := make([]poker.Card, 0, maxEvalCards)
+
+	// 同花：每个至少 5 位的牌集都用同一个花色
+	for flag := 0; flag < len(flushTable); flag++ {
+		n := _bitCount(uint32(flag))
+		if n < minEvalCards || n > maxEvalCards {
+			continue
+		}
+		cards = cards[:0]
+		for rank := 0; rank < rankSize; rank++ {
+			if flag&(1<<rank) != 0 {
+				cards = append(cards, _rangeCard(rank, 1))
+			}
+		}
+		h.SetCard(cards)
+		flushTable[flag] = h.FinalLevel()
+	}
+
+	// 非同花：花色轮流分配，每个花色最多两张，不会组成同花
+	for n := minEvalCards; n <= maxEvalCards; n++ {
+		noFlushTable[n] = make([]uint32, quinaryCount[rankSize][n])
+		var q [rankSize]uint8
+		var walk func(rank, left int)
+		walk = func(rank, left int) {
+			if rank == rankSize {
+				if left != 0 {
+					return
+				}
+				cards = cards[:0]
+				for r := 0; r < rankSize; r++ {
+					for i := 0; i < int(q[r]); i++ {
+						cards = append(cards, _rangeCard(r, uint32(len(cards))%SUIT_SIZE+1))
+					}
+				}
+				h.SetCard(cards)
+				noFlushTable[n][_hashQuinary(&q, n)] = h.FinalLevel()
+				return
+			}
+			for d := 0; d <= maxRankCount && d <= left; d++ {
+				q[rank] = uint8(d)
+				walk(rank+1, left-d)
+			}
+			q[rank] = 0
+		}
+		walk(0, n)
+	}
+}
+
+func _bitCount(x uint32) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
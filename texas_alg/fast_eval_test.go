@@ -0,0 +1,111 @@
+package texas_holdem
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func checkEvalCards(t *testing.T, h *Hand, cards []poker.Card) bool {
+	h.SetCard(cards)
+	if got := EvalCards(cards); got != h.FinalLevel() {
+		t.Errorf("cards %v: EvalCards %x, Hand %x (%s)", cards, got, h.FinalLevel(), HandTypeName(h.Level))
+		return false
+	}
+	return true
+}
+
+func TestEvalCardsAllFiveCards(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip exhaustive check in short mode")
+	}
+	h := NewHand()
+	h.SetNeedCalIndex(false)
+	cards := make([]poker.Card, 5)
+	_forEachCombination(len(poker.Deck), 5, func(idx []int) {
+		for i, j := range idx {
+			cards[i] = poker.Deck[j]
+		}
+		if !checkEvalCards(t, h, cards) {
+			t.FailNow()
+		}
+	})
+}
+
+func TestEvalCardsSampled(t *testing.T) {
+	h := NewHand()
+	h.SetNeedCalIndex(false)
+	r := rand.New(rand.NewSource(1))
+	deck := append([]poker.Card{}, poker.Deck...)
+
+	for n := 5; n <= 7; n++ {
+		for i := 0; i < 300000; i++ {
+			_partialShuffle(r, deck, n)
+			if !checkEvalCards(t, h, deck[:n]) {
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestEvalCardsSpecial(t *testing.T) {
+	h := NewHand()
+	for _, s := range []string{
+		"11, 131, 121, 111, 101, 21, 31", // 皇家同花顺
+		"11, 21, 31, 41, 51, 62, 72",     // A2345 同花顺
+		"11, 22, 33, 44, 51, 62, 72",     // A2345 顺子
+		"91, 92, 93, 94, 81, 82, 83",     // 四条带三条
+		"91, 92, 93, 81, 82, 83, 12",     // 两个三条
+		"141, 142, 143, 134, 133",        // A 用 14 表示
+	} {
+		checkEvalCards(t, h, poker.String2pokers(s))
+	}
+	if EvalCards(poker.String2pokers("11, 12, 13, 14")) != HandTypeUnknown {
+		t.Error("4 cards should be unknown")
+	}
+}
+
+func TestEvalCardsNoAlloc(t *testing.T) {
+	cards := poker.String2pokers("11, 32, 53, 74, 91, 112, 133")
+	EvalCards(cards)
+	if n := testing.AllocsPerRun(100, func() { EvalCards(cards) }); n != 0 {
+		t.Error("EvalCards should not allocate", n)
+	}
+}
+
+func benchmarkHands(n int) [][]poker.Card {
+	r := rand.New(rand.NewSource(1))
+	deck := append([]poker.Card{}, poker.Deck...)
+	hands := make([][]poker.Card, 1024)
+	for i := range hands {
+		_partialShuffle(r, deck, n)
+		hands[i] = append([]poker.Card{}, deck[:n]...)
+	}
+	return hands
+}
+
+func benchmarkEvalCards(b *testing.B, n int) {
+	hands := benchmarkHands(n)
+	EvalCards(hands[0])
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EvalCards(hands[i&1023])
+	}
+}
+
+func BenchmarkEvalCards5(b *testing.B) { benchmarkEvalCards(b, 5) }
+func BenchmarkEvalCards6(b *testing.B) { benchmarkEvalCards(b, 6) }
+func BenchmarkEvalCards7(b *testing.B) { benchmarkEvalCards(b, 7) }
+
+func BenchmarkHandSetCard7(b *testing.B) {
+	hands := benchmarkHands(7)
+	h := NewHand()
+	h.SetNeedCalIndex(false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.SetCard(hands[i&1023])
+	}
+}
@@ -0,0 +1,158 @@
+package texas_holdem
+
+import "fmt"
+
+// AnteType 前注的方式
+type AnteType int
+
+const (
+	AnteNone     AnteType = iota // 没有前注
+	AnteEveryone                 // 每个人下自己的前注，算在各自的下注里
+	AnteBigBlind                 // 大盲替所有人下前注，是死钱
+	AnteButton                   // 庄家替所有人下前注，是死钱
+)
+
+// StraddleType 抓头的方式，抓头是自愿的，由 ForcedSeat.Straddle 决定这一手抓不抓
+type StraddleType int
+
+const (
+	StraddleNone        StraddleType = iota // 不允许抓头
+	StraddleUTG                             // 大盲左手边的玩家抓头，翻牌前从抓头的左手边开始行动
+	StraddleMississippi                     // 庄家抓头，翻牌前从小盲开始行动，庄家最后行动
+)
+
+// PostType 强制下注的种类
+type PostType int
+
+const (
+	PostAnte           PostType = iota // 自己的前注
+	PostDeadAnte                       // 替所有人下的前注
+	PostSmallBlind                     // 小盲
+	PostBigBlind                       // 大盲
+	PostStraddle                       // 抓头
+	PostMissedBigBlind                 // 回来补的大盲，是活的
+	PostDeadSmallBlind                 // 回来补的小盲，是死钱
+)
+
+var postName = []string{"ante", "dead ante", "small blind", "big blind", "straddle", "missed big blind", "dead small blind"}
+
+func (t PostType) String() string {
+	if t >= 0 && int(t) < len(postName) {
+		return postName[t]
+	}
+	return "unknown"
+}
+
+// ForcedSeat 计算强制下注时一个座位的情况
+type ForcedSeat struct {
+	SeatID           int32
+	Stack            int64
+	Straddle         bool // 这一手想抓头
+	MissedSmallBlind bool // 错过了小盲，不在盲注位置时要补一个死的小盲
+	MissedBigBlind   bool // 错过了大盲，不在盲注位置时要补一个活的大盲
+}
+
+// Post 一次强制下注
+type Post struct {
+	SeatID int32
+	Type   PostType
+	Amount int64 // 筹码不够时只下剩下的
+}
+
+// Live 是否算这一轮的下注，算的话别人要跟
+func (p Post) Live() bool {
+	switch p.Type {
+	case PostSmallBlind, PostBigBlind, PostStraddle, PostMissedBigBlind:
+		return true
+	}
+	return false
+}
+
+// Dead 是否是死钱，放进底池但不算下的人的下注
+func (p Post) Dead() bool {
+	return p.Type == PostDeadAnte || p.Type == PostDeadSmallBlind
+}
+
+// ForcedBets 一手牌开始时所有的强制下注
+type ForcedBets struct {
+	Posts []Post // 按下的顺序：前注、盲注、抓头、补的盲注；筹码不够时前注优先
+	First int    // 翻牌前第一个行动的座位在 seats 里的下标
+}
+
+// ComputeForcedBets 按桌子的配置算出这一手每个座位的强制下注
+// seats 从庄家左手边开始顺时针，最后一个是庄家；sb bb 是小盲和大盲在 seats 里的下标，没有小盲时 sb 为 -1
+func ComputeForcedBets(config TableConfig, seats []ForcedSeat, sb, bb int) (*ForcedBets, error) {
+	n := len(seats)
+	if n < 2 || bb < 0 || bb >= n || sb < -1 || sb >= n || sb == bb {
+		return nil, fmt.Errorf("bad blind index sb %d bb %d in %d seats", sb, bb, n)
+	}
+	res := &ForcedBets{First: (bb + 1) % n}
+	stacks := make([]int64, n)
+	for i, s := range seats {
+		stacks[i] = s.Stack
+	}
+	post := func(i int, typ PostType, amount int64) {
+		amount = _min64(amount, stacks[i])
+		if amount <= 0 {
+			return
+		}
+		stacks[i] -= amount
+		res.Posts = append(res.Posts, Post{SeatID: seats[i].SeatID, Type: typ, Amount: amount})
+	}
+
+	if config.Ante > 0 {
+		switch config.AnteType {
+		case AnteEveryone:
+			for i := range seats {
+				post(i, PostAnte, config.Ante)
+			}
+		case AnteBigBlind:
+			post(bb, PostDeadAnte, config.Ante)
+		case AnteButton:
+			post(n-1, PostDeadAnte, config.Ante)
+		}
+	}
+
+	if sb >= 0 {
+		post(sb, PostSmallBlind, config.SmallBlind)
+	}
+	post(bb, PostBigBlind, config.BigBlind)
+
+	straddler := -1
+	if n > 2 {
+		switch config.Straddle {
+		case StraddleUTG:
+			straddler = (bb + 1) % n
+		case StraddleMississippi:
+			straddler = n - 1
+		}
+	}
+	if straddler >= 0 && straddler != sb && straddler != bb && seats[straddler].Straddle && stacks[straddler] > 0 {
+		amount := config.StraddleAmount
+		if amount == 0 {
+			amount = 2 * config.BigBlind
+		}
+		post(straddler, PostStraddle, amount)
+		if config.Straddle == StraddleUTG {
+			res.First = (straddler + 1) % n
+		} else {
+			res.First = 0
+		}
+	} else {
+		straddler = -1
+	}
+
+	for i, s := range seats {
+		if i == sb || i == bb {
+			continue
+		}
+		// 抓头已经比大盲多，不用再补大盲
+		if s.MissedBigBlind && i != straddler {
+			post(i, PostMissedBigBlind, config.BigBlind)
+		}
+		if s.MissedSmallBlind {
+			post(i, PostDeadSmallBlind, config.SmallBlind)
+		}
+	}
+	return res, nil
+}
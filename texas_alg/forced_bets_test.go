@@ -0,0 +1,167 @@
+package texas_holdem
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func forcedSeats(stacks ...int64) []ForcedSeat {
+	seats := make([]ForcedSeat, len(stacks))
+	for i, stack := range stacks {
+		seats[i] = ForcedSeat{SeatID: int32(i + 1), Stack: stack}
+	}
+	return seats
+}
+
+func TestForcedBetsAnteAndStraddle(t *testing.T) {
+	config := TableConfig{SmallBlind: 5, BigBlind: 10, Ante: 10, AnteType: AnteBigBlind, Straddle: StraddleUTG}
+	seats := forcedSeats(100, 100, 100, 100, 100)
+	seats[2].Straddle = true
+	seats[3].MissedSmallBlind = true
+	seats[3].MissedBigBlind = true
+
+	bets, err := ComputeForcedBets(config, seats, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Post{
+		{SeatID: 2, Type: PostDeadAnte, Amount: 10},
+		{SeatID: 1, Type: PostSmallBlind, Amount: 5},
+		{SeatID: 2, Type: PostBigBlind, Amount: 10},
+		{SeatID: 3, Type: PostStraddle, Amount: 20},
+		{SeatID: 4, Type: PostMissedBigBlind, Amount: 10},
+		{SeatID: 4, Type: PostDeadSmallBlind, Amount: 5},
+	}
+	if !reflect.DeepEqual(bets.Posts, expect) || bets.First != 3 {
+		t.Error("err posts", bets.Posts, bets.First)
+	}
+
+	// 庄家抓头从小盲开始行动
+	config.Straddle = StraddleMississippi
+	config.AnteType = AnteEveryone
+	config.Ante = 1
+	seats = forcedSeats(100, 100, 100, 1)
+	seats[3].Straddle = true
+	bets, err = ComputeForcedBets(config, seats, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = []Post{
+		{SeatID: 1, Type: PostAnte, Amount: 1},
+		{SeatID: 2, Type: PostAnte, Amount: 1},
+		{SeatID: 3, Type: PostAnte, Amount: 1},
+		{SeatID: 4, Type: PostAnte, Amount: 1},
+		{SeatID: 1, Type: PostSmallBlind, Amount: 5},
+		{SeatID: 2, Type: PostBigBlind, Amount: 10},
+	}
+	// 4 号下完前注就没有筹码抓头了
+	if !reflect.DeepEqual(bets.Posts, expect) || bets.First != 2 {
+		t.Error("err posts", bets.Posts, bets.First)
+	}
+}
+
+func TestForcedBetsBadIndex(t *testing.T) {
+	config := TableConfig{SmallBlind: 5, BigBlind: 10}
+	seats := forcedSeats(100, 100, 100)
+	for _, idx := range [][2]int{{0, -1}, {0, 3}, {3, 1}, {-2, 1}, {1, 1}} {
+		if _, err := ComputeForcedBets(config, seats, idx[0], idx[1]); err == nil {
+			t.Error("bad blind index should fail", idx)
+		}
+	}
+	if _, err := ComputeForcedBets(config, seats, -1, 1); err != nil {
+		t.Error("no small blind should work", err)
+	}
+}
+
+func TestTableStraddleAndDeadBlind(t *testing.T) {
+	table := newTestTable(t, 1000, 1000, 1000, 1000)
+	table.config.Straddle = StraddleUTG
+	table.SetStraddle(4, true)
+	table.SetMissedBlinds(1, true, false)
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	// 2 号小盲，3 号大盲，4 号抓头，1 号坐庄补死的小盲
+	if table.round.CurrentBet() != 20 || table.round.MinRaiseTo() != 40 || table.round.Pot() != 40 {
+		t.Error("err round", table.round.CurrentBet(), table.round.MinRaiseTo(), table.round.Pot())
+	}
+	mustAct(t, table, 1, Action{Type: ActionCall})
+	mustAct(t, table, 2, Action{Type: ActionCall})
+	mustAct(t, table, 3, Action{Type: ActionCall})
+	// 抓头的人最后还可以选择
+	if !hasAction(table.LegalActions(), ActionRaise) {
+		t.Error("straddle should have the option", table.LegalActions())
+	}
+	mustAct(t, table, 4, Action{Type: ActionCheck})
+
+	for table.Stage() != StageFinished {
+		seatID, _ := table.Turn()
+		mustAct(t, table, seatID, Action{Type: ActionCheck})
+	}
+	res := table.Result()
+	if res.Pots[0].Amount != 85 || res.Pots[0].Dead != 5 {
+		t.Error("err pot", res.Pots[0])
+	}
+	if totalStack(table, 1, 2, 3, 4) != 4000 {
+		t.Error("err total", totalStack(table, 1, 2, 3, 4))
+	}
+}
+
+func TestTableDeadAnteEligible(t *testing.T) {
+	dealer, err := poker.NewScriptedDealer(1, poker.Deck, poker.NewSeededRNG(1), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := NewTable(TableConfig{SmallBlind: 5, BigBlind: 10, Ante: 10, AnteType: AnteButton}, dealer)
+	for i, stack := range []int64{1000, 1000, 10} {
+		table.SitDown(int32(i+1), stack)
+	}
+	// 3 号坐庄，下完前注就全下了，拿到最大的牌只能赢前注
+	script, err := table.DealScript(3, map[int32][]poker.Card{
+		1: {poker.TwoSpades, poker.SevenHearts},
+		2: {poker.ThreeClubs, poker.EightDiamonds},
+		3: {poker.AceSpades, poker.AceHearts},
+	}, []poker.Card{poker.AceClubs, poker.KingDiamonds, poker.NineSpades, poker.FourHearts, poker.JackClubs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dealer.SetScript(script)
+	if err := table.StartHand(3); err != nil {
+		t.Fatal(err)
+	}
+	mustAct(t, table, 1, Action{Type: ActionCall})
+	mustAct(t, table, 2, Action{Type: ActionCheck})
+	for table.Stage() != StageFinished {
+		seatID, _ := table.Turn()
+		mustAct(t, table, seatID, Action{Type: ActionCheck})
+	}
+	res := table.Result()
+	if len(res.Pots) != 2 || res.Pots[0].Amount != 10 || res.Pots[0].Dead != 10 ||
+		!reflect.DeepEqual(res.Pots[0].Eligible, []int32{1, 2, 3}) || !reflect.DeepEqual(res.Pots[0].Winners, []int32{3}) {
+		t.Fatal("err dead pot", res.Pots[0])
+	}
+	if res.Pots[1].Amount != 20 || !reflect.DeepEqual(res.Pots[1].Eligible, []int32{1, 2}) {
+		t.Error("err main pot", res.Pots[1])
+	}
+	if table.Stack(3) != 10 || totalStack(table, 1, 2, 3) != 2010 {
+		t.Error("err stacks", table.Stack(3), totalStack(table, 1, 2, 3))
+	}
+}
+
+func TestAnteCommitted(t *testing.T) {
+	table := newTestTable(t, 1000, 1000, 1000)
+	table.config.Ante = 2
+	table.config.AnteType = AnteEveryone
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	mustAct(t, table, 1, Action{Type: ActionFold})
+	mustAct(t, table, 2, Action{Type: ActionFold})
+	res := table.Result()
+	// 前注算在各自的下注里，大盲多出来的 5 退还
+	if res.Uncalled == nil || res.Uncalled.Amount != 5 || res.Winnings[3] != 21 {
+		t.Error("err result", res.Uncalled, res.Winnings)
+	}
+}
@@ -240,7 +240,7 @@ func (h *Hand) Reset() {
 }
 
 func (h *Hand) SetCard(c []poker.Card) error {
-	if len(c) < 5 && len(c) > 7 {
+	if len(c) < 5 || len(c) > 7 {
 		return errors.New("卡牌个数不支持")
 	}
 
@@ -0,0 +1,270 @@
+package texas_holdem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+/* 手牌范围的写法，多个用逗号隔开
+---------------------------------------------------------------
+QQ        一对 Q，6 种组合
+QQ+       QQ KK AA
+22-55     22 33 44 55
+AKs       同花 AK，4 种组合
+AKo       不同花 AK，12 种组合
+AK        AKs + AKo，16 种组合
+AJo+      AJo AQo AKo，第二张牌往上加到比第一张小 1
+A2s-A5s   A2s A3s A4s A5s
+AsKd      指定花色的一手牌
+每一项后面可以加 :权重，例如 AKs:0.5，默认权重是 1，同一手牌出现多次时以最后一次为准
+牌值用 23456789TJQKA，花色用 s h d c（♠ ♥ ♦ ♣）
+*/
+
+const rangeRanks = "23456789TJQKA"
+
+// RangeCombo 范围里的一手具体的牌
+type RangeCombo struct {
+	Cards  [2]poker.Card
+	Weight float64
+}
+
+func (c RangeCombo) String() string {
+	return fmt.Sprintf("%v%v:%g", c.Cards[0], c.Cards[1], c.Weight)
+}
+
+// Range 手牌范围
+type Range []RangeCombo
+
+// ParseRange 解析 "QQ+,AKs,AJo+,KQs" 这样的范围写法
+func ParseRange(s string) (Range, error) {
+	var r Range
+	index := make(map[[2]poker.Card]int)
+
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		combos, err := _parseRangeToken(token)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range combos {
+			// AsKd 和 KdAs 是同一手牌，排成一样的顺序再去重
+			c.Cards = _canonicalCombo(c.Cards)
+			if i, ok := index[c.Cards]; ok {
+				r[i].Weight = c.Weight
+				continue
+			}
+			index[c.Cards] = len(r)
+			r = append(r, c)
+		}
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("empty range %q", s)
+	}
+	return r, nil
+}
+
+// RemoveDead 去掉和 dead 里的牌冲突的组合，权重为 0 的也一并去掉
+func (r Range) RemoveDead(dead []poker.Card) Range {
+	deadCard := make(map[poker.Card]bool)
+	for _, c := range dead {
+		deadCard[_normalizeCard(c)] = true
+	}
+	res := make(Range, 0, len(r))
+	for _, c := range r {
+		if c.Weight <= 0 || deadCard[c.Cards[0]] || deadCard[c.Cards[1]] {
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// Weight 范围内所有组合的权重之和，权重都是 1 时就是组合数
+func (r Range) Weight() float64 {
+	sum := 0.0
+	for _, c := range r {
+		sum += c.Weight
+	}
+	return sum
+}
+
+func _parseRangeToken(token string) ([]RangeCombo, error) {
+	weight := 1.0
+	if i := strings.IndexByte(token, ':'); i >= 0 {
+		w, err := strconv.ParseFloat(token[i+1:], 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("bad weight in range %q", token)
+		}
+		token, weight = token[:i], w
+	}
+
+	var combos [][2]poker.Card
+	var err error
+	if dash := strings.IndexByte(token, '-'); dash >= 0 {
+		combos, err = _parseRangeSpan(token[:dash], token[dash+1:])
+	} else if strings.HasSuffix(token, "+") {
+		combos, err = _parseRangePlus(strings.TrimSuffix(token, "+"))
+	} else if len(token) == 4 {
+		combos, err = _parseRangeExact(token)
+	} else {
+		var h rangeHand
+		h, err = _parseRangeHand(token)
+		combos = h.combos()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]RangeCombo, len(combos))
+	for i, c := range combos {
+		res[i] = RangeCombo{Cards: c, Weight: weight}
+	}
+	return res, nil
+}
+
+// rangeHand 一类手牌，例如 AKs，high low 是 rangeRanks 里的下标
+type rangeHand struct {
+	high, low int
+	suited    byte // 's' 同花 'o' 不同花 0 都要
+}
+
+func _parseRangeHand(s string) (h rangeHand, err error) {
+	if len(s) != 2 && len(s) != 3 {
+		return h, fmt.Errorf("bad hand %q in range", s)
+	}
+	h.high = strings.IndexByte(rangeRanks, s[0])
+	h.low = strings.IndexByte(rangeRanks, s[1])
+	if h.high < 0 || h.low < 0 {
+		return h, fmt.Errorf("bad rank in hand %q", s)
+	}
+	if h.high < h.low {
+		h.high, h.low = h.low, h.high
+	}
+	if len(s) == 3 {
+		h.suited = s[2]
+		if (h.suited != 's' && h.suited != 'o') || h.high == h.low {
+			return h, fmt.Errorf("bad suit flag in hand %q", s)
+		}
+	}
+	return h, nil
+}
+
+// combos 展开成具体的牌
+func (h rangeHand) combos() [][2]poker.Card {
+	var res [][2]poker.Card
+	for s1 := uint32(1); s1 <= SUIT_SIZE; s1++ {
+		for s2 := uint32(1); s2 <= SUIT_SIZE; s2++ {
+			if h.high == h.low && s2 <= s1 {
+				continue
+			}
+			if (h.suited == 's' && s1 != s2) || (h.suited == 'o' && s1 == s2) {
+				continue
+			}
+			res = append(res, [2]poker.Card{_rangeCard(h.high, s1), _rangeCard(h.low, s2)})
+		}
+	}
+	return res
+}
+
+// _canonicalCombo 牌值大的在前，牌值一样时花色小的在前，和 rangeHand.combos 的顺序一致
+func _canonicalCombo(cards [2]poker.Card) [2]poker.Card {
+	rank := func(c poker.Card) uint32 {
+		if c.Value() == 1 {
+			return ACE_VALUE
+		}
+		return c.Value()
+	}
+	a, b := cards[0], cards[1]
+	if rank(a) < rank(b) || (rank(a) == rank(b) && a.Suit() > b.Suit()) {
+		a, b = b, a
+	}
+	return [2]poker.Card{a, b}
+}
+
+func _rangeCard(rank int, suit uint32) poker.Card {
+	value := uint32(rank + 2)
+	if value == ACE_VALUE {
+		value = 1
+	}
+	return poker.MakeCard(value, suit)
+}
+
+// _parseRangePlus QQ+ 往上加到 AA，AJo+ 第二张牌往上加到比第一张小 1
+func _parseRangePlus(s string) ([][2]poker.Card, error) {
+	h, err := _parseRangeHand(s)
+	if err != nil {
+		return nil, err
+	}
+	var res [][2]poker.Card
+	if h.high == h.low {
+		for rank := h.high; rank < len(rangeRanks); rank++ {
+			res = append(res, rangeHand{high: rank, low: rank}.combos()...)
+		}
+		return res, nil
+	}
+	for low := h.low; low < h.high; low++ {
+		res = append(res, rangeHand{high: h.high, low: low, suited: h.suited}.combos()...)
+	}
+	return res, nil
+}
+
+// _parseRangeSpan 22-55 或者 A2s-A5s
+func _parseRangeSpan(from, to string) ([][2]poker.Card, error) {
+	a, err := _parseRangeHand(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := _parseRangeHand(to)
+	if err != nil {
+		return nil, err
+	}
+	if a.suited != b.suited {
+		return nil, fmt.Errorf("bad range %s-%s", from, to)
+	}
+
+	var res [][2]poker.Card
+	switch {
+	case a.high == a.low && b.high == b.low:
+		lo, hi := a.high, b.high
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for rank := lo; rank <= hi; rank++ {
+			res = append(res, rangeHand{high: rank, low: rank}.combos()...)
+		}
+	case a.high == b.high && a.high != a.low && b.high != b.low:
+		lo, hi := a.low, b.low
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for low := lo; low <= hi; low++ {
+			res = append(res, rangeHand{high: a.high, low: low, suited: a.suited}.combos()...)
+		}
+	default:
+		return nil, fmt.Errorf("bad range %s-%s", from, to)
+	}
+	return res, nil
+}
+
+// _parseRangeExact AsKd 这种指定了花色的
+func _parseRangeExact(s string) ([][2]poker.Card, error) {
+	var cards [2]poker.Card
+	for i := 0; i < 2; i++ {
+		rank := strings.IndexByte(rangeRanks, s[i*2])
+		suit := strings.IndexByte("dchs", s[i*2+1])
+		if rank < 0 || suit < 0 {
+			return nil, fmt.Errorf("bad hand %q in range", s)
+		}
+		cards[i] = _rangeCard(rank, uint32(suit+1))
+	}
+	if cards[0] == cards[1] {
+		return nil, fmt.Errorf("duplicate card in hand %q", s)
+	}
+	return [][2]poker.Card{cards}, nil
+}
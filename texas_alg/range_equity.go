@@ -0,0 +1,150 @@
+package texas_holdem
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+// RangeEquity 两个手牌范围对抗的胜率，返回值 [0] 是 hero，[1] 是 villain
+// 先去掉和公共牌冲突的组合，两边的组合互相冲突的不算；每一对组合的权重是两边权重的乘积
+// 需要枚举的发牌组合不超过 EXACT_ENUMERATION_LIMIT 时穷举，否则按 opt 采样
+func RangeEquity(hero, villain Range, public []poker.Card, opt MonteCarloOptions) ([]EquityEstimate, error) {
+	if len(public) > BOARD_SIZE {
+		return nil, fmt.Errorf("too many public cards: %d", len(public))
+	}
+	cardShowed := make(map[poker.Card]bool)
+	for _, c := range public {
+		c = _normalizeCard(c)
+		if !_isDeckCard(c) || cardShowed[c] {
+			return nil, fmt.Errorf("bad public card %v", c)
+		}
+		cardShowed[c] = true
+	}
+	hero = hero.RemoveDead(public)
+	villain = villain.RemoveDead(public)
+
+	pairs := 0
+	for _, h := range hero {
+		for _, v := range villain {
+			if !_combosConflict(h, v) {
+				pairs++
+			}
+		}
+	}
+	if pairs == 0 {
+		return nil, errors.New("no valid combos left in ranges")
+	}
+
+	rest := _restDeck(cardShowed)
+	boardMissing := BOARD_SIZE - len(public)
+	deals := float64(pairs) * _combination(len(rest)-2*HOLE_CARD_SIZE, boardMissing)
+	if deals > EXACT_ENUMERATION_LIMIT {
+		return _rangeEquityMonteCarlo(hero, villain, public, rest, opt), nil
+	}
+	return _rangeEquityExact(hero, villain, public, rest, int(deals)), nil
+}
+
+func _combosConflict(a, b RangeCombo) bool {
+	return a.Cards[0] == b.Cards[0] || a.Cards[0] == b.Cards[1] ||
+		a.Cards[1] == b.Cards[0] || a.Cards[1] == b.Cards[1]
+}
+
+// _restWithout 从 rest 里去掉两手牌用到的牌，结果写进 buf
+func _restWithout(buf, rest []poker.Card, h, v RangeCombo) []poker.Card {
+	buf = buf[:0]
+	for _, c := range rest {
+		if c != h.Cards[0] && c != h.Cards[1] && c != v.Cards[0] && c != v.Cards[1] {
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+func _rangeEquityExact(hero, villain Range, public, rest []poker.Card, deals int) []EquityEstimate {
+	counter := newEquityCounter(2)
+	hands := _newHands(2)
+	board := make([]poker.Card, BOARD_SIZE)
+	copy(board, public)
+	buf := make([]poker.Card, 0, HOLE_CARD_SIZE+BOARD_SIZE)
+	live := make([]poker.Card, 0, len(rest))
+
+	for _, h := range hero {
+		for _, v := range villain {
+			if _combosConflict(h, v) {
+				continue
+			}
+			weight := h.Weight * v.Weight
+			live = _restWithout(live, rest, h, v)
+			_forEachCombination(len(live), BOARD_SIZE-len(public), func(idx []int) {
+				for i, j := range idx {
+					board[len(public)+i] = live[j]
+				}
+				_evalHand(hands[0], buf, h.Cards[:], board)
+				_evalHand(hands[1], buf, v.Cards[:], board)
+				counter.addWeighted(hands, weight)
+			})
+		}
+	}
+
+	eq := counter.result()
+	res := make([]EquityEstimate, len(eq))
+	for i := range eq {
+		res[i] = EquityEstimate{SeatEquity: eq[i], Samples: deals, Low: eq[i].Equity, High: eq[i].Equity}
+	}
+	return res
+}
+
+// _rangeEquityMonteCarlo 两边按权重各抽一手牌，冲突就重抽，这样每一对组合被抽中的概率正比于权重的乘积
+func _rangeEquityMonteCarlo(hero, villain Range, public, rest []poker.Card, opt MonteCarloOptions) []EquityEstimate {
+	counter := newEquityCounter(2)
+	hands := _newHands(2)
+	board := make([]poker.Card, BOARD_SIZE)
+	copy(board, public)
+	buf := make([]poker.Card, 0, HOLE_CARD_SIZE+BOARD_SIZE)
+	live := make([]poker.Card, 0, len(rest))
+	r := opt.random()
+	heroCum, villainCum := hero.cumulative(), villain.cumulative()
+	boardMissing := BOARD_SIZE - len(public)
+
+	opt.run(func() {
+		var h, v RangeCombo
+		for {
+			h = hero[_pickWeighted(heroCum, r.Float64())]
+			v = villain[_pickWeighted(villainCum, r.Float64())]
+			if !_combosConflict(h, v) {
+				break
+			}
+		}
+		live = _restWithout(live, rest, h, v)
+		_partialShuffle(r, live, boardMissing)
+		copy(board[len(public):], live[:boardMissing])
+		_evalHand(hands[0], buf, h.Cards[:], board)
+		_evalHand(hands[1], buf, v.Cards[:], board)
+		counter.add(hands)
+	})
+	return counter.estimate()
+}
+
+// cumulative 权重的前缀和
+func (r Range) cumulative() []float64 {
+	cum := make([]float64, len(r))
+	sum := 0.0
+	for i, c := range r {
+		sum += c.Weight
+		cum[i] = sum
+	}
+	return cum
+}
+
+// _pickWeighted 按前缀和 cum 抽一个下标，u 是 [0, 1) 的随机数
+func _pickWeighted(cum []float64, u float64) int {
+	x := u * cum[len(cum)-1]
+	i := sort.Search(len(cum), func(i int) bool { return cum[i] > x })
+	if i >= len(cum) {
+		i = len(cum) - 1
+	}
+	return i
+}
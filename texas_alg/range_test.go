@@ -0,0 +1,136 @@
+package texas_holdem
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		r      string
+		combos int
+	}{
+		{"QQ", 6},
+		{"QQ+", 18},
+		{"22-55", 24},
+		{"AKs", 4},
+		{"AKo", 12},
+		{"AK", 16},
+		{"AJo+", 36},
+		{"A2s-A5s", 16},
+		{"AsKd", 1},
+		{"QQ+,AKs,AJo+,KQs", 18 + 4 + 36 + 4},
+		{"AK, AKs", 16},
+	}
+	for _, c := range cases {
+		r, err := ParseRange(c.r)
+		if err != nil {
+			t.Error(c.r, err)
+			continue
+		}
+		if len(r) != c.combos {
+			t.Error("err combos", c.r, len(r), c.combos)
+		}
+	}
+
+	for _, bad := range []string{"", "QQs", "AX", "AKx", "AK:abc", "AsAs", "QQ-AKs"} {
+		if _, err := ParseRange(bad); err == nil {
+			t.Error("should fail", bad)
+		}
+	}
+}
+
+func TestParseRangeOverlap(t *testing.T) {
+	weight := func(r Range, a, b poker.Card) []float64 {
+		var res []float64
+		for _, c := range r {
+			if (c.Cards[0] == a && c.Cards[1] == b) || (c.Cards[0] == b && c.Cards[1] == a) {
+				res = append(res, c.Weight)
+			}
+		}
+		return res
+	}
+	kd, as := poker.KingDiamonds, poker.AceSpades
+	qs, qd := poker.QueenSpades, poker.QueenDiamonds
+	for _, s := range []string{"AK,KdAs:0.5,QQ,QsQd:0", "AK,AsKd:0.5,QQ,QdQs:0"} {
+		r, err := ParseRange(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r) != 16+6 {
+			t.Error("err combos", s, len(r))
+		}
+		if w := weight(r, as, kd); len(w) != 1 || w[0] != 0.5 {
+			t.Error("err AsKd", s, w)
+		}
+		if w := weight(r, qs, qd); len(w) != 1 || w[0] != 0 {
+			t.Error("err QsQd", s, w)
+		}
+	}
+
+	// 反过来写：后面的通用写法覆盖前面指定花色的
+	r, err := ParseRange("KdAs:0.5,AK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := weight(r, as, kd); len(r) != 16 || len(w) != 1 || w[0] != 1 {
+		t.Error("err KdAs", len(r), w)
+	}
+}
+
+func TestRangeRemoveDead(t *testing.T) {
+	r, _ := ParseRange("AA,KK:0.5")
+	if r.Weight() != 6+3 {
+		t.Error("err weight", r.Weight())
+	}
+	r = r.RemoveDead(poker.String2pokers("14, 131"))
+	if len(r) != 3+3 || r.Weight() != 3+1.5 {
+		t.Error("err dead combos", r)
+	}
+}
+
+func TestRangeEquity(t *testing.T) {
+	public := poker.String2pokers("21, 32, 73, 94, 102")
+
+	hero, _ := ParseRange("AsAh")
+	villain, _ := ParseRange("KsKh")
+	res, err := RangeEquity(hero, villain, public, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Win != 1 {
+		t.Error("AA should win", res)
+	}
+
+	// 权重为 0 的组合不参与计算
+	villain, _ = ParseRange("KK:0,AKs")
+	res1, _ := RangeEquity(hero, villain, public, MonteCarloOptions{})
+	villain, _ = ParseRange("AKs")
+	res2, _ := RangeEquity(hero, villain, public, MonteCarloOptions{})
+	if res1[0] != res2[0] {
+		t.Error("zero weight combos should be ignored", res1[0], res2[0])
+	}
+}
+
+func TestRangeEquityMonteCarlo(t *testing.T) {
+	hero, _ := ParseRange("AA")
+	villain, _ := ParseRange("KK")
+
+	opt := MonteCarloOptions{Samples: 50000, Rand: rand.New(rand.NewSource(3))}
+	res, err := RangeEquity(hero, villain, nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("AA vs KK", res[0].SeatEquity, "stderr", res[0].StdErr)
+	if res[0].Samples != 50000 || math.Abs(res[0].Equity-0.82) > 0.015 {
+		t.Error("err AA vs KK equity", res[0])
+	}
+
+	if _, err := RangeEquity(hero, hero, poker.String2pokers("11, 12, 13"), opt); err == nil {
+		t.Error("ranges without valid combos should fail")
+	}
+}
@@ -0,0 +1,184 @@
+package texas_holdem
+
+import (
+	"errors"
+	"sort"
+)
+
+// NO_SEAT 没有这个位置，比如死小盲
+const NO_SEAT int32 = -1
+
+// Positions 一手牌的庄家和盲注位置
+type Positions struct {
+	Button     int32   // 庄家的位置，死庄时这个座位可能没人
+	SmallBlind int32   // 小盲，死小盲时为 NO_SEAT；两个人时就是庄家
+	BigBlind   int32   // 大盲
+	Order      []int32 // 这一手参与的座位，从庄家左手边开始顺时针，也是翻牌后的行动顺序
+}
+
+// Index 座位在 Order 里的下标，不在这一手里返回 -1
+func (p *Positions) Index(seatID int32) int {
+	if seatID == NO_SEAT {
+		return -1
+	}
+	for i, s := range p.Order {
+		if s == seatID {
+			return i
+		}
+	}
+	return -1
+}
+
+// ActionOrder 这条街的行动顺序
+// 翻牌前从大盲左手边开始，大盲最后；翻牌后从庄家左手边开始，庄家（或者庄家右手边第一个）最后
+// 两个人时翻牌前庄家先行动，翻牌后大盲先行动；抓头改变的翻牌前顺序见 ForcedBets.First
+func (p *Positions) ActionOrder(street Stage) []int32 {
+	first := 0
+	if street <= StagePreflop {
+		first = (p.Index(p.BigBlind) + 1) % len(p.Order)
+	}
+	return append(append([]int32(nil), p.Order[first:]...), p.Order[:first]...)
+}
+
+// ButtonPositions 按庄家位置直接算出盲注：庄家左手边下小盲，再下一个下大盲；两个人时庄家下小盲
+// active 是这一手参与的座位
+func ButtonPositions(active []int32, button int32) (*Positions, error) {
+	active = _sortedSeats(active)
+	if len(active) < 2 {
+		return nil, errors.New("not enough players")
+	}
+	if len(active) == 2 {
+		if button != active[0] && button != active[1] {
+			button = _nextSeat(active, button)
+		}
+		return _headsUpPositions(active, button), nil
+	}
+	sb := _nextSeat(active, button)
+	bb := _nextSeat(active, sb)
+	return &Positions{
+		Button:     button,
+		SmallBlind: sb,
+		BigBlind:   bb,
+		Order:      _seatOrder(active, button, sb),
+	}, nil
+}
+
+func _headsUpPositions(active []int32, button int32) *Positions {
+	bb := _nextSeat(active, button)
+	return &Positions{
+		Button:     button,
+		SmallBlind: button,
+		BigBlind:   bb,
+		Order:      []int32{bb, button},
+	}
+}
+
+// SeatRotation 按死庄规则在每一手之间移动庄家和盲注
+// 大盲每手往左移到下一个参与的玩家，小盲落在上一手大盲的位置，庄家落在上一手小盲的位置：
+// 上一手的大盲走了或者暂时离开就没有小盲（死小盲），上一手小盲的位置没人就是死庄；
+// 坐在庄家和小盲之间的新玩家要等庄家过去才能参与，这样每个人都不会跳过或者多下盲注；
+// 两个人时庄家下小盲，大盲不会连续两手是同一个人
+type SeatRotation struct {
+	started    bool
+	button     int32 // 上一手的庄家位置
+	smallBlind int32 // 上一手的小盲位置，死小盲时也记位置
+	bigBlind   int32 // 上一手的大盲
+}
+
+// Started 是否已经开始过
+func (r *SeatRotation) Started() bool {
+	return r.started
+}
+
+// Set 用指定的位置开始，之后从这里往下轮
+func (r *SeatRotation) Set(p *Positions) {
+	r.started = true
+	r.button = p.Button
+	r.smallBlind = p.SmallBlind
+	r.bigBlind = p.BigBlind
+}
+
+// Next 下一手的位置，active 是下一手可以参与的座位
+// 第一手从座位号最小的玩家坐庄开始
+func (r *SeatRotation) Next(active []int32) (*Positions, error) {
+	active = _sortedSeats(active)
+	if len(active) < 2 {
+		return nil, errors.New("not enough players")
+	}
+	if !r.started {
+		p, err := ButtonPositions(active, active[0])
+		if err == nil {
+			r.Set(p)
+		}
+		return p, err
+	}
+
+	bb := _nextSeat(active, r.bigBlind)
+	var p *Positions
+	if len(active) == 2 {
+		p = _headsUpPositions(active, _nextSeat(active, bb))
+		r.Set(p)
+		return p, nil
+	}
+
+	button, sbPos := r.smallBlind, r.bigBlind
+	p = &Positions{
+		Button:     button,
+		SmallBlind: NO_SEAT,
+		BigBlind:   bb,
+		Order:      _seatOrder(active, button, sbPos),
+	}
+	if bb == button || _seatBetween(button, sbPos, bb) || len(p.Order) < 2 {
+		// 上一手的人都走了，大盲绕回到庄家和小盲之间，按普通规则往下轮
+		fresh, err := ButtonPositions(active, _nextSeat(active, r.button))
+		if err == nil {
+			r.Set(fresh)
+		}
+		return fresh, err
+	}
+	if _containsSeat(active, sbPos) {
+		p.SmallBlind = sbPos
+	}
+	r.started = true
+	r.button, r.smallBlind, r.bigBlind = button, sbPos, bb
+	return p, nil
+}
+
+// _seatOrder 从庄家左手边开始顺时针的座位，跳过庄家和小盲位置之间的座位
+func _seatOrder(active []int32, button, sbPos int32) []int32 {
+	start := sort.Search(len(active), func(i int) bool { return active[i] > button })
+	order := make([]int32, 0, len(active))
+	for i := range active {
+		seatID := active[(start+i)%len(active)]
+		if _seatBetween(button, sbPos, seatID) {
+			continue
+		}
+		order = append(order, seatID)
+	}
+	return order
+}
+
+// _nextSeat 顺时针在 seatID 之后的第一个座位，active 排好序
+func _nextSeat(active []int32, seatID int32) int32 {
+	i := sort.Search(len(active), func(i int) bool { return active[i] > seatID })
+	return active[i%len(active)]
+}
+
+// _seatBetween 顺时针从 from 到 to（都不包括）之间是否经过 seatID
+func _seatBetween(from, to, seatID int32) bool {
+	if from < to {
+		return seatID > from && seatID < to
+	}
+	return seatID > from || seatID < to
+}
+
+func _containsSeat(active []int32, seatID int32) bool {
+	i := sort.Search(len(active), func(i int) bool { return active[i] >= seatID })
+	return i < len(active) && active[i] == seatID
+}
+
+func _sortedSeats(seats []int32) []int32 {
+	res := append([]int32(nil), seats...)
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
@@ -0,0 +1,158 @@
+package texas_holdem
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func checkPositions(t *testing.T, p *Positions, button, sb, bb int32, order ...int32) {
+	t.Helper()
+	if p.Button != button || p.SmallBlind != sb || p.BigBlind != bb || !reflect.DeepEqual(p.Order, order) {
+		t.Errorf("err positions %+v, expect button %d sb %d bb %d order %v", p, button, sb, bb, order)
+	}
+}
+
+func mustNext(t *testing.T, r *SeatRotation, active ...int32) *Positions {
+	t.Helper()
+	p, err := r.Next(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestSeatRotationDeadButton(t *testing.T) {
+	r := &SeatRotation{}
+	checkPositions(t, mustNext(t, r, 1, 2, 3, 4, 5), 1, 2, 3, 2, 3, 4, 5, 1)
+
+	// 3 号大盲走了：4 号大盲，没有小盲，庄家移到上一手小盲 2 号
+	checkPositions(t, mustNext(t, r, 1, 2, 4, 5), 2, NO_SEAT, 4, 4, 5, 1, 2)
+	// 庄家落在没人的 3 号（死庄）
+	checkPositions(t, mustNext(t, r, 1, 2, 4, 5), 3, 4, 5, 4, 5, 1, 2)
+	checkPositions(t, mustNext(t, r, 1, 2, 4, 5), 4, 5, 1, 5, 1, 2, 4)
+}
+
+func TestSeatRotationNewPlayerWaits(t *testing.T) {
+	r := &SeatRotation{}
+	p, err := ButtonPositions([]int32{1, 3, 5, 7}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Set(p)
+	checkPositions(t, p, 1, 3, 5, 3, 5, 7, 1)
+
+	// 4 号坐在庄家和小盲之间，要等庄家过去
+	p = mustNext(t, r, 1, 3, 4, 5, 7)
+	checkPositions(t, p, 3, 5, 7, 5, 7, 1, 3)
+	if order := p.ActionOrder(StagePreflop); !reflect.DeepEqual(order, []int32{1, 3, 5, 7}) {
+		t.Error("err preflop order", order)
+	}
+	if order := p.ActionOrder(StageFlop); !reflect.DeepEqual(order, []int32{5, 7, 1, 3}) {
+		t.Error("err flop order", order)
+	}
+	checkPositions(t, mustNext(t, r, 1, 3, 4, 5, 7), 5, 7, 1, 7, 1, 3, 4, 5)
+}
+
+func TestSeatRotationHeadsUp(t *testing.T) {
+	r := &SeatRotation{}
+	checkPositions(t, mustNext(t, r, 1, 2, 3), 1, 2, 3, 2, 3, 1)
+
+	// 2 号走了变成两个人：上一手大盲 3 号坐庄下小盲，1 号下大盲
+	p := mustNext(t, r, 1, 3)
+	checkPositions(t, p, 3, 3, 1, 1, 3)
+	if order := p.ActionOrder(StagePreflop); !reflect.DeepEqual(order, []int32{3, 1}) {
+		t.Error("err preflop order", order)
+	}
+	if order := p.ActionOrder(StageRiver); !reflect.DeepEqual(order, []int32{1, 3}) {
+		t.Error("err river order", order)
+	}
+	checkPositions(t, mustNext(t, r, 1, 3), 1, 1, 3, 3, 1)
+
+	// 回到三个人：大盲轮到上一手的庄家 1 号，2 号直接坐庄
+	checkPositions(t, mustNext(t, r, 1, 2, 3), 2, 3, 1, 3, 1, 2)
+	if _, err := r.Next([]int32{1}); err == nil {
+		t.Error("one player should fail")
+	}
+}
+
+func TestTableSitOutMissedBlind(t *testing.T) {
+	table := newTestTable(t, 1000, 1000, 1000, 1000)
+	foldAround := func() {
+		for table.Stage() != StageFinished {
+			seatID, _ := table.Turn()
+			mustAct(t, table, seatID, Action{Type: ActionFold})
+		}
+	}
+
+	if err := table.StartNextHand(); err != nil {
+		t.Fatal(err)
+	}
+	checkPositions(t, table.Positions(), 1, 2, 3, 2, 3, 4, 1)
+	foldAround()
+
+	// 4 号暂时离开，大盲跳过他
+	table.SitOut(4)
+	if err := table.StartNextHand(); err != nil {
+		t.Fatal(err)
+	}
+	checkPositions(t, table.Positions(), 2, 3, 1, 3, 1, 2)
+	foldAround()
+	if !table.seat(4).MissedBigBlind {
+		t.Error("seat 4 should miss the big blind")
+	}
+
+	// 4 号回来，但是坐在庄家和小盲之间，这一手还要等
+	table.SitIn(4)
+	if err := table.StartNextHand(); err != nil {
+		t.Fatal(err)
+	}
+	checkPositions(t, table.Positions(), 3, 1, 2, 1, 2, 3)
+	foldAround()
+
+	if err := table.StartNextHand(); err != nil {
+		t.Fatal(err)
+	}
+	checkPositions(t, table.Positions(), 1, 2, 3, 2, 3, 4, 1)
+	posts := table.Posts()
+	if last := posts[len(posts)-1]; last != (Post{SeatID: 4, Type: PostMissedBigBlind, Amount: 10}) {
+		t.Error("seat 4 should post the missed big blind", posts)
+	}
+	foldAround()
+	if totalStack(table, 1, 2, 3, 4) != 4000 {
+		t.Error("err total", totalStack(table, 1, 2, 3, 4))
+	}
+}
+
+func TestSeatRotationEveryoneLeft(t *testing.T) {
+	r := &SeatRotation{}
+	p, err := ButtonPositions([]int32{1, 3, 9}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Set(p)
+
+	// 庄家和小盲都走了，新来的 5 7 号坐在庄家和小盲之间，大盲不能落在跳过的座位上
+	checkPositions(t, mustNext(t, r, 5, 7, 9), 5, 7, 9, 7, 9, 5)
+
+	table := NewTable(TableConfig{SmallBlind: 5, BigBlind: 10}, poker.NewDealer(1, poker.Deck))
+	for _, seatID := range []int32{1, 3, 9} {
+		table.SitDown(seatID, 1000)
+	}
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	for table.Stage() != StageFinished {
+		seatID, _ := table.Turn()
+		mustAct(t, table, seatID, Action{Type: ActionFold})
+	}
+	table.Leave(1)
+	table.Leave(3)
+	table.SitDown(5, 1000)
+	table.SitDown(7, 1000)
+	if err := table.StartNextHand(); err != nil {
+		t.Fatal(err)
+	}
+	checkPositions(t, table.Positions(), 5, 7, 9, 7, 9, 5)
+}
@@ -0,0 +1,128 @@
+package texas_holdem
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+// ShowdownSeat 摊牌时一个座位的信息
+type ShowdownSeat struct {
+	SeatID    int32
+	HoleCards []poker.Card
+	Folded    bool  // 已经弃牌
+	BetAmount int64 // 这一手一共下注的金额
+}
+
+// ShowdownResult 摊牌结果
+type ShowdownResult struct {
+	Hands     map[int32]*Hand // 每个没弃牌的座位的牌力，只剩一个人没弃牌时不比牌，为 nil
+	Ranking   [][]int32       // 没弃牌的座位从大到小排列，同一组的牌力相同（平局）
+	BetStatus []IBetStatus    // 可以直接交给 DistributePond
+}
+
+// Showdown 多人摊牌比牌
+// 弃牌的座位 WinVal 为 0，其它座位为 FinalLevel() + 1；只剩一个人没弃牌时不需要手牌和完整的公共牌
+func Showdown(public []poker.Card, seats []ShowdownSeat) (*ShowdownResult, error) {
+	live := 0
+	seen := make(map[int32]bool, len(seats))
+	for _, s := range seats {
+		if seen[s.SeatID] {
+			return nil, fmt.Errorf("duplicate seat %d", s.SeatID)
+		}
+		seen[s.SeatID] = true
+		if !s.Folded {
+			live++
+		}
+	}
+	if live == 0 {
+		return nil, fmt.Errorf("all seats folded")
+	}
+
+	res := &ShowdownResult{
+		Hands:     make(map[int32]*Hand, live),
+		BetStatus: make([]IBetStatus, len(seats)),
+	}
+	if live > 1 {
+		if err := _checkShowdownCards(public, seats); err != nil {
+			return nil, err
+		}
+	}
+
+	levels := make(map[int32]uint32, live)
+	order := make([]int32, 0, live)
+	cards := make([]poker.Card, 0, HOLE_CARD_SIZE+BOARD_SIZE)
+	for i, s := range seats {
+		winVal := uint32(0)
+		if !s.Folded {
+			order = append(order, s.SeatID)
+			if live > 1 {
+				h := NewHand()
+				cards = append(append(cards[:0], s.HoleCards...), public...)
+				h.SetCard(cards)
+				res.Hands[s.SeatID] = h
+				levels[s.SeatID] = h.FinalLevel()
+			}
+			winVal = levels[s.SeatID] + 1
+		}
+		res.BetStatus[i] = NewBetStatus(s.SeatID, winVal, s.BetAmount)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return levels[order[i]] > levels[order[j]]
+	})
+	for i, seatID := range order {
+		if i == 0 || levels[seatID] != levels[order[i-1]] {
+			res.Ranking = append(res.Ranking, nil)
+		}
+		last := len(res.Ranking) - 1
+		res.Ranking[last] = append(res.Ranking[last], seatID)
+	}
+	return res, nil
+}
+
+func _checkShowdownCards(public []poker.Card, seats []ShowdownSeat) error {
+	if len(public) != BOARD_SIZE {
+		return fmt.Errorf("showdown needs %d public cards, got %d", BOARD_SIZE, len(public))
+	}
+	cardShowed := make(map[poker.Card]bool)
+	mark := func(c poker.Card) error {
+		c = _normalizeCard(c)
+		if !_isDeckCard(c) {
+			return fmt.Errorf("invalid card %d", uint32(c))
+		}
+		if cardShowed[c] {
+			return fmt.Errorf("duplicate card %v", c)
+		}
+		cardShowed[c] = true
+		return nil
+	}
+	for _, c := range public {
+		if err := mark(c); err != nil {
+			return err
+		}
+	}
+	for _, s := range seats {
+		if s.Folded {
+			continue
+		}
+		if len(s.HoleCards) != HOLE_CARD_SIZE {
+			return fmt.Errorf("seat %d has %d hole cards", s.SeatID, len(s.HoleCards))
+		}
+		for _, c := range s.HoleCards {
+			if err := mark(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Winners 排名第一的座位（可能有多个）
+func (r *ShowdownResult) Winners() []int32 {
+	if len(r.Ranking) == 0 {
+		return nil
+	}
+	return r.Ranking[0]
+}
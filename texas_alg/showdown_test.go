@@ -0,0 +1,84 @@
+package texas_holdem
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func TestShowdown(t *testing.T) {
+	public := poker.String2pokers("21, 32, 73, 94, 102")
+	seats := []ShowdownSeat{
+		{SeatID: 1, HoleCards: poker.String2pokers("14, 13"), BetAmount: 100},
+		{SeatID: 2, HoleCards: poker.String2pokers("134, 133"), BetAmount: 100},
+		{SeatID: 3, HoleCards: poker.String2pokers("12, 11"), BetAmount: 100},
+		{SeatID: 4, HoleCards: poker.String2pokers("132, 131"), BetAmount: 50, Folded: true},
+	}
+
+	res, err := Showdown(public, seats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Ranking, [][]int32{{1, 3}, {2}}) {
+		t.Error("err ranking", res.Ranking)
+	}
+	if res.Hands[4] != nil || res.BetStatus[3].WinVal() != 0 {
+		t.Error("folded seat should not be ranked")
+	}
+	if res.BetStatus[0].WinVal() != res.Hands[1].FinalLevel()+1 {
+		t.Error("err win val", res.BetStatus[0])
+	}
+
+	win := DistributePond(res.BetStatus)
+	if win[1] != 175 || win[3] != 175 || win[2] != 0 {
+		t.Error("err distribute", win)
+	}
+}
+
+func TestShowdownEveryoneFolded(t *testing.T) {
+	seats := []ShowdownSeat{
+		{SeatID: 1, BetAmount: 20, Folded: true},
+		{SeatID: 2, BetAmount: 40},
+	}
+	res, err := Showdown(nil, seats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Winners(), []int32{2}) {
+		t.Error("err winners", res.Ranking)
+	}
+	if win := DistributePond(res.BetStatus); win[2] != 60 {
+		t.Error("err distribute", win)
+	}
+}
+
+func TestShowdownBadInput(t *testing.T) {
+	public := poker.String2pokers("21, 32, 73, 94, 102")
+	if _, err := Showdown(public, []ShowdownSeat{
+		{SeatID: 1, HoleCards: poker.String2pokers("21, 13")},
+		{SeatID: 2, HoleCards: poker.String2pokers("134, 133")},
+	}); err == nil {
+		t.Error("duplicate card should fail")
+	}
+	if _, err := Showdown(public[:3], []ShowdownSeat{
+		{SeatID: 1, HoleCards: poker.String2pokers("14, 13")},
+		{SeatID: 2, HoleCards: poker.String2pokers("134, 133")},
+	}); err == nil {
+		t.Error("incomplete board should fail")
+	}
+	// 花色为 0 的牌不能让比牌 panic
+	if _, err := Showdown(public, []ShowdownSeat{
+		{SeatID: 1, HoleCards: []poker.Card{0x10, 0x20}},
+		{SeatID: 2, HoleCards: poker.String2pokers("134, 133")},
+	}); err == nil {
+		t.Error("invalid hole card should fail")
+	}
+	bad := append([]poker.Card{0x3f}, public[1:]...)
+	if _, err := Showdown(bad, []ShowdownSeat{
+		{SeatID: 1, HoleCards: poker.String2pokers("14, 13")},
+		{SeatID: 2, HoleCards: poker.String2pokers("134, 133")},
+	}); err == nil {
+		t.Error("invalid board card should fail")
+	}
+}
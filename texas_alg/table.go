@@ -0,0 +1,564 @@
+package texas_holdem
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+// Stage 一手牌进行到的阶段
+type Stage int
+
+const (
+	StageWaiting  Stage = iota // 等待开始
+	StageBlinds                // 下盲注
+	StageDealHole              // 发手牌
+	StagePreflop               // 翻牌前下注
+	StageFlop                  // 翻牌圈下注
+	StageTurn                  // 转牌圈下注
+	StageRiver                 // 河牌圈下注
+	StageShowdown              // 摊牌
+	StagePayout                // 派奖
+	StageFinished              // 这一手结束
+)
+
+var stageName = []string{
+	"等待开始",
+	"下盲注",
+	"发手牌",
+	"翻牌前",
+	"翻牌",
+	"转牌",
+	"河牌",
+	"摊牌",
+	"派奖",
+	"结束",
+}
+
+func (s Stage) String() string {
+	if s >= 0 && int(s) < len(stageName) {
+		return stageName[s]
+	}
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+// TableConfig 桌子的配置
+type TableConfig struct {
+	SmallBlind     int64
+	BigBlind       int64
+	Ante           int64            // 前注，AnteBigBlind 和 AnteButton 时是替所有人下的总额
+	AnteType       AnteType         // 前注的方式
+	Straddle       StraddleType     // 允许的抓头方式
+	StraddleAmount int64            // 抓头的金额，0 表示两个大盲
+	Structure      BettingStructure // 下注结构，nil 为无限注
+}
+
+// TablePlayer 坐在桌上的玩家
+type TablePlayer struct {
+	BettingSeat
+	HoleCards []poker.Card // 这一手的手牌
+
+	SittingOut       bool // 暂时离开，不参与下一手
+	Straddle         bool // 下一手想抓头，开始之后清掉
+	MissedSmallBlind bool // 错过了小盲，下一手要补
+	MissedBigBlind   bool // 错过了大盲，下一手要补
+}
+
+// Table 一张桌子，驱动一手牌从下盲注、发牌、各轮下注到摊牌派奖
+type Table struct {
+	config TableConfig
+	dealer poker.IDealer
+	seats  []*TablePlayer // 所有坐下的玩家，按座位号排好
+
+	stage     Stage
+	button    int32
+	rotation  SeatRotation
+	positions *Positions     // 这一手的庄家和盲注位置
+	players   []*TablePlayer // 这一手参与的玩家，从庄家左手边开始顺时针，最后一个是庄家
+	posts     []Post         // 这一手的强制下注
+	public    []poker.Card
+	round     *BettingRound // 当前这一轮下注
+
+	showdown *ShowdownResult
+	result   *PondResult
+}
+
+func NewTable(config TableConfig, dealer poker.IDealer) *Table {
+	return &Table{
+		config: config,
+		dealer: dealer,
+	}
+}
+
+// SitDown 玩家坐下，只能在两手牌之间
+// 已经开始轮庄之后坐下的玩家要补一个大盲才能参与，轮到他下大盲时不用补
+func (t *Table) SitDown(seatID int32, stack int64) error {
+	if t.inHand() {
+		return errors.New("hand in progress")
+	}
+	if seatID == NO_SEAT {
+		return fmt.Errorf("invalid seat %d", seatID)
+	}
+	if t.seat(seatID) != nil {
+		return fmt.Errorf("seat %d already taken", seatID)
+	}
+	t.seats = append(t.seats, &TablePlayer{
+		BettingSeat:    BettingSeat{SeatID: seatID, Stack: stack},
+		MissedBigBlind: t.rotation.Started(),
+	})
+	sort.Slice(t.seats, func(i, j int) bool { return t.seats[i].SeatID < t.seats[j].SeatID })
+	return nil
+}
+
+// Leave 玩家离开，只能在两手牌之间
+func (t *Table) Leave(seatID int32) error {
+	if t.inHand() {
+		return errors.New("hand in progress")
+	}
+	for i, p := range t.seats {
+		if p.SeatID == seatID {
+			t.seats = append(t.seats[:i], t.seats[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("seat %d is empty", seatID)
+}
+
+// SitOut 玩家暂时离开，从下一手开始不参与，被盲注跳过时记下错过的盲注
+func (t *Table) SitOut(seatID int32) error {
+	p := t.seat(seatID)
+	if p == nil {
+		return fmt.Errorf("seat %d is empty", seatID)
+	}
+	p.SittingOut = true
+	return nil
+}
+
+// SitIn 暂时离开的玩家回来，从下一手开始参与，错过的盲注要补
+func (t *Table) SitIn(seatID int32) error {
+	p := t.seat(seatID)
+	if p == nil {
+		return fmt.Errorf("seat %d is empty", seatID)
+	}
+	p.SittingOut = false
+	return nil
+}
+
+func (t *Table) seat(seatID int32) *TablePlayer {
+	for _, p := range t.seats {
+		if p.SeatID == seatID {
+			return p
+		}
+	}
+	return nil
+}
+
+func (t *Table) inHand() bool {
+	return t.stage != StageWaiting && t.stage != StageFinished
+}
+
+// Stage 当前阶段
+func (t *Table) Stage() Stage {
+	return t.stage
+}
+
+// Public 当前的公共牌
+func (t *Table) Public() []poker.Card {
+	return append([]poker.Card(nil), t.public...)
+}
+
+// Players 这一手参与的玩家的状态，从庄家左手边开始
+func (t *Table) Players() []TablePlayer {
+	res := make([]TablePlayer, len(t.players))
+	for i, p := range t.players {
+		res[i] = *p
+	}
+	return res
+}
+
+// Stack 座位上玩家的筹码
+func (t *Table) Stack(seatID int32) int64 {
+	if p := t.seat(seatID); p != nil {
+		return p.Stack
+	}
+	return 0
+}
+
+// Turn 轮到哪个座位行动
+func (t *Table) Turn() (seatID int32, ok bool) {
+	if t.round == nil {
+		return 0, false
+	}
+	return t.round.Turn()
+}
+
+// Positions 这一手的庄家和盲注位置
+func (t *Table) Positions() *Positions {
+	return t.positions
+}
+
+// Posts 这一手开始时的强制下注
+func (t *Table) Posts() []Post {
+	return append([]Post(nil), t.posts...)
+}
+
+// SetStraddle 设置玩家下一手是否抓头，只能在两手牌之间
+func (t *Table) SetStraddle(seatID int32, straddle bool) error {
+	if t.inHand() {
+		return errors.New("hand in progress")
+	}
+	p := t.seat(seatID)
+	if p == nil {
+		return fmt.Errorf("seat %d is empty", seatID)
+	}
+	p.Straddle = straddle
+	return nil
+}
+
+// SetMissedBlinds 设置玩家错过的盲注，下一手不在盲注位置时要补，只能在两手牌之间
+func (t *Table) SetMissedBlinds(seatID int32, small, big bool) error {
+	if t.inHand() {
+		return errors.New("hand in progress")
+	}
+	p := t.seat(seatID)
+	if p == nil {
+		return fmt.Errorf("seat %d is empty", seatID)
+	}
+	p.MissedSmallBlind = small
+	p.MissedBigBlind = big
+	return nil
+}
+
+// Burned 这一手烧掉的牌
+func (t *Table) Burned() []poker.Card {
+	return t.dealer.Burned()
+}
+
+// Audit 这一手发牌的审计记录，手牌的接收者是座位号
+func (t *Table) Audit() *poker.DealerAudit {
+	return t.dealer.Audit()
+}
+
+// Showdown 摊牌结果，这一手结束之后才有
+func (t *Table) Showdown() *ShowdownResult {
+	return t.showdown
+}
+
+// Result 底池分配结果，这一手结束之后才有
+func (t *Table) Result() *PondResult {
+	return t.result
+}
+
+// StartHand 指定庄家开始新的一手，之后的 StartNextHand 从这里按死庄规则往下轮
+// button 是庄家的座位号，筹码为 0 和暂时离开的玩家不参与
+func (t *Table) StartHand(button int32) error {
+	if t.inHand() {
+		return errors.New("hand in progress")
+	}
+	if t.seat(button) == nil {
+		return fmt.Errorf("button seat %d is empty", button)
+	}
+	positions, err := ButtonPositions(t.activeSeats(), button)
+	if err != nil {
+		return err
+	}
+	restore := t.snapshot()
+	if err := t.startHand(positions); err != nil {
+		restore()
+		return err
+	}
+	// 开始成功之后才从这里往下轮庄
+	t.rotation.Set(positions)
+	return nil
+}
+
+// DealScript 把按座位号指定的手牌换成 poker.Script，发牌顺序和以 button 为庄家的 StartHand 一致
+// 配合 poker.ScriptedDealer 搭测试场景，没指定的座位和公共牌随机
+func (t *Table) DealScript(button int32, holes map[int32][]poker.Card, board []poker.Card) (*poker.Script, error) {
+	positions, err := ButtonPositions(t.activeSeats(), button)
+	if err != nil {
+		return nil, err
+	}
+	script := &poker.Script{
+		Seats: len(positions.Order),
+		Holes: make([][]poker.Card, len(positions.Order)),
+		Board: board,
+	}
+	for seatID, cards := range holes {
+		i := positions.Index(seatID)
+		if i < 0 {
+			return nil, fmt.Errorf("seat %d is not in the hand", seatID)
+		}
+		script.Holes[i] = cards
+	}
+	return script, nil
+}
+
+// StartNextHand 按死庄规则移动庄家和盲注，开始新的一手
+func (t *Table) StartNextHand() error {
+	if t.inHand() {
+		return errors.New("hand in progress")
+	}
+	restore := t.snapshot()
+	prev := t.rotation
+	positions, err := t.rotation.Next(t.activeSeats())
+	if err != nil {
+		restore()
+		return err
+	}
+	if prev.Started() {
+		t.markMissedBlinds(prev.bigBlind, positions.BigBlind)
+	}
+	if err := t.startHand(positions); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// snapshot 记下桌子和所有玩家现在的状态，开始新的一手失败时调用返回的函数恢复：
+// 下的盲注、前注退回，庄家和错过的盲注不变，桌子回到两手牌之间
+func (t *Table) snapshot() (restore func()) {
+	saved := *t
+	players := make([]TablePlayer, len(t.seats))
+	for i, p := range t.seats {
+		players[i] = *p
+	}
+	return func() {
+		*t = saved
+		for i, p := range t.seats {
+			*p = players[i]
+		}
+	}
+}
+
+// activeSeats 下一手可以参与的座位
+func (t *Table) activeSeats() []int32 {
+	var active []int32
+	for _, p := range t.seats {
+		if p.Stack > 0 && !p.SittingOut {
+			active = append(active, p.SeatID)
+		}
+	}
+	return active
+}
+
+// markMissedBlinds 大盲从 prevBB 移到 bb，中间暂时离开的玩家错过了大盲，
+// 暂时离开的 prevBB 这一手该下小盲，错过了小盲
+func (t *Table) markMissedBlinds(prevBB, bb int32) {
+	for _, p := range t.seats {
+		if !p.SittingOut {
+			continue
+		}
+		if _seatBetween(prevBB, bb, p.SeatID) {
+			p.MissedBigBlind = true
+		}
+		if p.SeatID == prevBB {
+			p.MissedSmallBlind = true
+		}
+	}
+}
+
+// startHand 按位置开始新的一手：下前注、盲注、抓头，洗牌发手牌，进入翻牌前下注
+// 出错时状态是改了一半的，调用方要用 snapshot 恢复
+func (t *Table) startHand(positions *Positions) error {
+	// 先洗牌，出错时还没有动任何状态
+	if err := t.dealer.Shuffle(); err != nil {
+		return err
+	}
+
+	// 不复用原来的切片，失败时 snapshot 才能恢复
+	t.players = make([]*TablePlayer, 0, len(positions.Order))
+	for _, seatID := range positions.Order {
+		p := t.seat(seatID)
+		p.BettingSeat = BettingSeat{SeatID: p.SeatID, Stack: p.Stack}
+		p.HoleCards = nil
+		t.players = append(t.players, p)
+	}
+
+	t.positions = positions
+	t.button = positions.Button
+	t.public = nil
+	t.showdown = nil
+	t.result = nil
+
+	t.round = nil
+
+	t.stage = StageBlinds
+	sb, bb := positions.Index(positions.SmallBlind), positions.Index(positions.BigBlind)
+	forced := make([]ForcedSeat, len(t.players))
+	for i, p := range t.players {
+		forced[i] = ForcedSeat{
+			SeatID:           p.SeatID,
+			Stack:            p.Stack,
+			Straddle:         p.Straddle,
+			MissedSmallBlind: p.MissedSmallBlind,
+			MissedBigBlind:   p.MissedBigBlind,
+		}
+		p.Straddle = false
+		p.MissedSmallBlind = false
+		p.MissedBigBlind = false
+	}
+	bets, err := ComputeForcedBets(t.config, forced, sb, bb)
+	if err != nil {
+		return err
+	}
+	t.posts = bets.Posts
+	for _, post := range bets.Posts {
+		t.post(t.seat(post.SeatID), post)
+	}
+
+	t.stage = StageDealHole
+	recipients := make([]string, len(t.players))
+	for i, p := range t.players {
+		recipients[i] = strconv.Itoa(int(p.SeatID))
+	}
+	holes, err := poker.DealHoleCards(t.dealer, t.stage.String(), recipients, HOLE_CARD_SIZE)
+	if err != nil {
+		return err
+	}
+	for i, p := range t.players {
+		p.HoleCards = holes[i]
+	}
+
+	t.stage = StagePreflop
+	t.round = NewBettingRoundWithStructure(t.bettingSeats(), bets.First, t.structure(), t.stage)
+	if t.round.Closed() {
+		t.endStreet()
+	}
+	return nil
+}
+
+func (t *Table) structure() BettingStructure {
+	if t.config.Structure == nil {
+		return NoLimit{BigBlind: t.config.BigBlind}
+	}
+	return t.config.Structure
+}
+
+func (t *Table) bettingSeats() []*BettingSeat {
+	seats := make([]*BettingSeat, len(t.players))
+	for i, p := range t.players {
+		seats[i] = &p.BettingSeat
+	}
+	return seats
+}
+
+// post 下一次强制下注
+func (t *Table) post(p *TablePlayer, post Post) {
+	switch {
+	case post.Live():
+		p.putChips(post.Amount)
+	case post.Dead():
+		p.putDead(post.Amount)
+	default:
+		p.putAnte(post.Amount)
+	}
+}
+
+// LegalActions 当前轮到的玩家可以做的动作
+func (t *Table) LegalActions() []LegalAction {
+	if t.round == nil {
+		return nil
+	}
+	return t.round.LegalActions()
+}
+
+// Act 轮到的玩家行动，不合法时返回 *ActionError
+// 这一轮下注结束时自动发下一条街的牌，最后摊牌派奖
+func (t *Table) Act(seatID int32, a Action) error {
+	if t.round == nil {
+		return &ActionError{SeatID: seatID, Action: a, Err: ErrRoundClosed}
+	}
+	if err := t.round.Act(seatID, a); err != nil {
+		return err
+	}
+	if t.round.Closed() {
+		t.endStreet()
+	}
+	return nil
+}
+
+// endStreet 一轮下注结束，发下一条街或者摊牌
+func (t *Table) endStreet() {
+	for {
+		live := 0
+		for _, p := range t.players {
+			p.StreetBet = 0
+			if !p.Folded {
+				live++
+			}
+		}
+
+		if live < 2 || t.stage == StageRiver {
+			t.finish()
+			return
+		}
+
+		var n int
+		switch t.stage {
+		case StagePreflop:
+			n = 3
+		default:
+			n = 1
+		}
+		cards, err := poker.DealStreet(t.dealer, (t.stage + 1).String(), n)
+		if err != nil {
+			t.finish()
+			return
+		}
+		t.public = append(t.public, cards...)
+		t.stage++
+
+		t.round = NewBettingRoundWithStructure(t.bettingSeats(), 0, t.structure(), t.stage)
+		if !t.round.Closed() {
+			return
+		}
+	}
+}
+
+// finish 摊牌并派奖
+func (t *Table) finish() {
+	t.round = nil
+	t.stage = StageShowdown
+
+	seats := make([]ShowdownSeat, len(t.players))
+	holeCards := make(map[int32][]poker.Card, len(t.players))
+	dead := int64(0)
+	for i, p := range t.players {
+		dead += p.DeadBet
+		seats[i] = ShowdownSeat{
+			SeatID:    p.SeatID,
+			HoleCards: p.HoleCards,
+			Folded:    p.Folded,
+			BetAmount: p.TotalBet,
+		}
+		holeCards[p.SeatID] = p.HoleCards
+	}
+	showdown, err := Showdown(t.public, seats)
+	if err != nil {
+		// 发牌出错时没法比牌，退还所有下注
+		for _, p := range t.players {
+			p.Stack += p.TotalBet + p.DeadBet
+		}
+		t.stage = StageFinished
+		return
+	}
+	t.showdown = showdown
+
+	t.stage = StagePayout
+	t.result = DistributePondDetail(showdown.BetStatus, &PondOptions{
+		OddChip:    OddChipLeftOfButton,
+		ButtonSeat: t.button,
+		HoleCards:  holeCards,
+		FlopSeen:   len(t.public) >= 3,
+		DeadMoney:  dead,
+	})
+	for _, p := range t.players {
+		p.Stack += t.result.Winnings[p.SeatID]
+	}
+	t.stage = StageFinished
+}
@@ -0,0 +1,268 @@
+package texas_holdem
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zack-wong/TexasDemo/poker"
+)
+
+func newTestTable(t *testing.T, stacks ...int64) *Table {
+	table := NewTable(TableConfig{SmallBlind: 5, BigBlind: 10}, poker.NewDealer(1, poker.Deck))
+	for i, stack := range stacks {
+		if err := table.SitDown(int32(i+1), stack); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return table
+}
+
+func mustAct(t *testing.T, table *Table, seatID int32, a Action) {
+	t.Helper()
+	turn, ok := table.Turn()
+	if !ok || turn != seatID {
+		t.Fatalf("expect seat %d to act, got %d %v", seatID, turn, ok)
+	}
+	if err := table.Act(seatID, a); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func totalStack(table *Table, seats ...int32) int64 {
+	sum := int64(0)
+	for _, s := range seats {
+		sum += table.Stack(s)
+	}
+	return sum
+}
+
+func TestTableCheckDown(t *testing.T) {
+	table := newTestTable(t, 1000, 1000, 1000)
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	// 1 是庄家，2 小盲，3 大盲，翻牌前 1 先说话
+	if table.Stage() != StagePreflop {
+		t.Fatal("err stage", table.Stage())
+	}
+	mustAct(t, table, 1, Action{Type: ActionCall})
+	mustAct(t, table, 2, Action{Type: ActionCall})
+	mustAct(t, table, 3, Action{Type: ActionCheck})
+
+	for _, stage := range []Stage{StageFlop, StageTurn, StageRiver} {
+		if table.Stage() != stage {
+			t.Fatal("err stage", table.Stage(), stage)
+		}
+		for _, seat := range []int32{2, 3, 1} {
+			mustAct(t, table, seat, Action{Type: ActionCheck})
+		}
+	}
+
+	if table.Stage() != StageFinished || len(table.Public()) != 5 {
+		t.Fatal("hand should be finished", table.Stage(), table.Public())
+	}
+	if totalStack(table, 1, 2, 3) != 3000 || table.Result() == nil {
+		t.Error("chips lost")
+	}
+	if len(table.Burned()) != 3 {
+		t.Error("should burn before flop, turn and river", table.Burned())
+	}
+	// 手牌从庄家左手边的 2 号开始一人一张，接收者是座位号
+	audit := table.Audit()
+	if len(audit.Events) != 6+3+5 {
+		t.Fatal("err audit events", len(audit.Events))
+	}
+	for i, seat := range []string{"2", "3", "1", "2", "3", "1"} {
+		if e := audit.Events[i]; e.Recipient != seat || e.Street != StageDealHole.String() {
+			t.Error("err hole event", i, e)
+		}
+	}
+	if e := audit.Events[6]; e.Action != poker.AuditBurn || e.Street != StageFlop.String() {
+		t.Error("err burn event", e)
+	}
+}
+
+func TestTableFoldToBigBlind(t *testing.T) {
+	table := newTestTable(t, 1000, 1000, 1000)
+	table.StartHand(1)
+	mustAct(t, table, 1, Action{Type: ActionFold})
+	mustAct(t, table, 2, Action{Type: ActionFold})
+
+	if table.Stage() != StageFinished {
+		t.Fatal("err stage", table.Stage())
+	}
+	if table.Stack(3) != 1005 || table.Stack(2) != 995 || table.Stack(1) != 1000 {
+		t.Error("err stacks", table.Stack(1), table.Stack(2), table.Stack(3))
+	}
+}
+
+func TestTableHeadsUpAllIn(t *testing.T) {
+	table := newTestTable(t, 300, 1000)
+	table.StartHand(1)
+	// 两个人时庄家下小盲，翻牌前先说话
+	players := table.Players()
+	if players[1].SeatID != 1 || players[1].TotalBet != 5 || players[0].TotalBet != 10 {
+		t.Fatal("err blinds", players)
+	}
+
+	legal := table.LegalActions()
+	if legal[len(legal)-1].Type != ActionAllIn || legal[len(legal)-1].Max != 295 {
+		t.Error("err legal actions", legal)
+	}
+	if err := table.Act(2, Action{Type: ActionCheck}); err == nil {
+		t.Error("acting out of turn should fail")
+	}
+	if err := table.Act(1, Action{Type: ActionRaise, Amount: 15}); err == nil {
+		t.Error("raise below minimum should fail")
+	}
+	mustAct(t, table, 1, Action{Type: ActionAllIn})
+	mustAct(t, table, 2, Action{Type: ActionCall})
+
+	if table.Stage() != StageFinished || len(table.Public()) != 5 {
+		t.Fatal("board should run out", table.Stage(), table.Public())
+	}
+	if totalStack(table, 1, 2) != 1300 {
+		t.Error("chips lost", table.Stack(1), table.Stack(2))
+	}
+}
+
+func TestTableSeededReplay(t *testing.T) {
+	play := func() (*Table, []TablePlayer) {
+		table := NewTable(TableConfig{SmallBlind: 5, BigBlind: 10}, poker.NewSeededDealer(1, poker.Deck, 99))
+		for i := int32(1); i <= 3; i++ {
+			table.SitDown(i, 1000)
+		}
+		if err := table.StartHand(1); err != nil {
+			t.Fatal(err)
+		}
+		for table.Stage() != StageFinished {
+			seatID, _ := table.Turn()
+			a := Action{Type: ActionCheck}
+			if table.Stage() == StagePreflop {
+				a.Type = ActionCall
+				if seatID == 3 {
+					a.Type = ActionCheck
+				}
+			}
+			mustAct(t, table, seatID, a)
+		}
+		return table, table.Players()
+	}
+
+	a, playersA := play()
+	b, playersB := play()
+	if !reflect.DeepEqual(playersA, playersB) || !reflect.DeepEqual(a.Public(), b.Public()) ||
+		!reflect.DeepEqual(a.Result().Winnings, b.Result().Winnings) {
+		t.Error("same seed should replay the same hand")
+	}
+}
+
+func TestTableScriptedSetOverSet(t *testing.T) {
+	dealer, err := poker.NewScriptedDealer(1, poker.Deck, poker.NewSeededRNG(3), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := NewTable(TableConfig{SmallBlind: 5, BigBlind: 10}, dealer)
+	for i := int32(1); i <= 3; i++ {
+		table.SitDown(i, 1000)
+	}
+	// 翻牌 2 号暗三 9 对 3 号暗三 5，河牌 3 号中最后一张 5 变四条
+	script, err := table.DealScript(1, map[int32][]poker.Card{
+		2: {poker.NineSpades, poker.NineHearts},
+		3: {poker.FiveClubs, poker.FiveDiamonds},
+	}, []poker.Card{poker.NineClubs, poker.FiveSpades, poker.KingDiamonds, poker.TwoClubs, poker.FiveHearts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dealer.SetScript(script); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range table.Players() {
+		if p.SeatID == 2 && !reflect.DeepEqual(p.HoleCards, []poker.Card{poker.NineSpades, poker.NineHearts}) {
+			t.Fatal("err hole cards", p.HoleCards)
+		}
+	}
+	mustAct(t, table, 1, Action{Type: ActionFold})
+	mustAct(t, table, 2, Action{Type: ActionCall})
+	mustAct(t, table, 3, Action{Type: ActionCheck})
+	public := table.Public()
+	if !reflect.DeepEqual(public, []poker.Card{poker.NineClubs, poker.FiveSpades, poker.KingDiamonds}) {
+		t.Fatal("err flop", public)
+	}
+	mustAct(t, table, 2, Action{Type: ActionBet, Amount: 990})
+	mustAct(t, table, 3, Action{Type: ActionCall})
+
+	if table.Stage() != StageFinished {
+		t.Fatal("err stage", table.Stage())
+	}
+	if winners := table.Showdown().Winners(); !reflect.DeepEqual(winners, []int32{3}) {
+		t.Error("err winners", winners, table.Public())
+	}
+
+	if _, err := table.DealScript(1, map[int32][]poker.Card{7: {poker.AceSpades}}, nil); err == nil {
+		t.Error("expect error for empty seat")
+	}
+}
+
+// failingDealer 发手牌时出错的发牌器
+type failingDealer struct {
+	*poker.Dealer
+	fail bool
+}
+
+func (d *failingDealer) DealTo(street, recipient string) (poker.Card, int, error) {
+	if d.fail {
+		return 0, 0, errors.New("dealer broken")
+	}
+	return d.Dealer.DealTo(street, recipient)
+}
+
+func TestTableStartHandFailure(t *testing.T) {
+	dealer := &failingDealer{Dealer: poker.NewSeededDealer(1, poker.Deck, 1), fail: true}
+	table := NewTable(TableConfig{SmallBlind: 5, BigBlind: 10, Ante: 1, AnteType: AnteEveryone}, dealer)
+	for i := int32(1); i <= 3; i++ {
+		table.SitDown(i, 1000)
+	}
+	table.SetMissedBlinds(3, false, true)
+
+	if err := table.StartHand(1); err == nil {
+		t.Fatal("expect error")
+	}
+	// 盲注和前注退回，不算开始了一手，也没有开始轮庄
+	if table.Stage() == StageDealHole || totalStack(table, 1, 2, 3) != 3000 || table.Stack(2) != 1000 {
+		t.Fatal("failed start should be rolled back", table.Stage(), table.Stack(1), table.Stack(2), table.Stack(3))
+	}
+	if table.rotation.Started() || !table.seat(3).MissedBigBlind {
+		t.Error("failed start should not move the button or clear missed blinds")
+	}
+
+	dealer.fail = false
+	if err := table.StartHand(1); err != nil {
+		t.Fatal(err)
+	}
+	for table.Stage() != StageFinished {
+		seatID, _ := table.Turn()
+		mustAct(t, table, seatID, Action{Type: ActionFold})
+	}
+	button := table.Positions().Button
+
+	// 往下轮庄失败时庄家不动，修好之后轮到的还是同一个人
+	dealer.fail = true
+	if err := table.StartNextHand(); err == nil {
+		t.Fatal("expect error")
+	}
+	if table.Positions().Button != button || totalStack(table, 1, 2, 3) != 3000 {
+		t.Fatal("failed next hand should be rolled back", table.Positions().Button)
+	}
+	dealer.fail = false
+	if err := table.StartNextHand(); err != nil {
+		t.Fatal(err)
+	}
+	if next := table.Positions().Button; next == button {
+		t.Error("button should move after a successful start", next)
+	}
+}
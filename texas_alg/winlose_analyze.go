@@ -2,6 +2,7 @@ package texas_holdem
 
 import (
 	"fmt"
+	"math/rand"
 	"strings"
 
 	"github.com/zack-wong/TexasDemo/poker"
@@ -32,73 +33,38 @@ func WinloseAnalyzeByPokerStr(poker string) (winOrTieRate float32, err error) {
 	player := CardsStr2Cards(cards[0])
 	banker := CardsStr2Cards(cards[1])
 	log15.Warn(fmt.Sprintf("===> win lost player %#v banker %#v ", player, banker))
-	return WinloseAnalyze(player, banker), nil
+	return _winloseAnalyze(player, banker, MonteCarloOptions{
+		Rand: rand.New(rand.NewSource(WINLOSE_ANALYZE_SEED)),
+	})
 }
 
-const (
-	counter_win = iota
-	counter_lose
-	counter_tie
-)
+// WINLOSE_ANALYZE_SEED WinloseAnalyze 采样用的固定种子，同样的输入每次结果一样
+const WINLOSE_ANALYZE_SEED = 20200101
 
+// WinloseAnalyze 单挑一个随机对手时的胜率（赢或者平），公共牌可以是 0~5 张
+// 发牌组合不超过 EXACT_ENUMERATION_LIMIT 时（转牌、河牌）穷举，结果精确；
+// 翻牌前、翻牌时用固定种子采样 DEFAULT_MONTE_CARLO_SAMPLES 次，是估算值，但同样的输入每次结果一样。
+// 需要换种子或者采样次数时用 WinloseAnalyzeWithOptions
 func WinloseAnalyze(player, public []poker.Card) (winOrTieRate float32) {
-	counter := make([]int32, 3)
-	cardShowed := make(map[poker.Card]bool)
-	for _, c := range player {
-		cardShowed[c] = true
-	}
-	for _, c := range public {
-		cardShowed[c] = true
-	}
-
-	handPlayer := NewHand()
-	handPlayer.SetNeedCalIndex(false)
-	handBanker := NewHand()
-	handBanker.SetNeedCalIndex(false)
-
-	banker := make([]poker.Card, 0, 2)
+	return WinloseAnalyzeWithOptions(player, public, MonteCarloOptions{
+		Rand: rand.New(rand.NewSource(WINLOSE_ANALYZE_SEED)),
+	})
+}
 
-	if len(public) >= 5 {
-		_innerAnalyze(player, public, banker, 0, cardShowed, handPlayer, handBanker, counter)
-	} else {
-		for i := 0; i < len(poker.Deck); i++ {
-			if cardShowed[poker.Deck[i]] {
-				continue
-			}
-			public2 := append(public, poker.Deck[i])
-			_innerAnalyze(player, public2, banker, i, cardShowed, handPlayer, handBanker, counter)
-		}
+// WinloseAnalyzeWithOptions 同 WinloseAnalyze，需要采样时按 opt 采样，opt.Rand 为 nil 时每次结果不同
+func WinloseAnalyzeWithOptions(player, public []poker.Card, opt MonteCarloOptions) (winOrTieRate float32) {
+	rate, err := _winloseAnalyze(player, public, opt)
+	if err != nil {
+		log15.Warn(fmt.Sprintf("===> win lost analyze player %v public %v err %v", player, public, err))
+		return 0
 	}
-
-	winOrTieCount := counter[counter_win] + counter[counter_tie]
-	totalCount := winOrTieCount + counter[counter_lose]
-
-	return float32(winOrTieCount) / float32(totalCount)
-
+	return rate
 }
 
-func _innerAnalyze(player, public, banker []poker.Card,
-	i int, cardShowed map[poker.Card]bool, handPlayer, handBanker *Hand, counter []int32) {
-	handPlayer.SetCard(append(player, public...))
-
-	for j := i + 1; j < len(poker.Deck); j++ {
-		if cardShowed[poker.Deck[j]] {
-			continue
-		}
-		banker1 := append(banker, poker.Deck[j])
-		for k := j + 1; k < len(poker.Deck); k++ {
-			if cardShowed[poker.Deck[k]] {
-				continue
-			}
-			banker2 := append(banker1, poker.Deck[k])
-			handBanker.SetCard(append(banker2, public...))
-			if handPlayer.Win(handBanker) {
-				counter[counter_win]++
-			} else if handPlayer.Tie(handBanker) {
-				counter[counter_tie]++
-			} else {
-				counter[counter_lose]++
-			}
-		}
+func _winloseAnalyze(player, public []poker.Card, opt MonteCarloOptions) (float32, error) {
+	res, err := EquityCalculate([][]poker.Card{player}, public, 1, opt)
+	if err != nil {
+		return 0, err
 	}
+	return float32(res[0].Win + res[0].Tie), nil
 }
@@ -0,0 +1,153 @@
+package tournament
+
+import (
+	"sync"
+	"time"
+)
+
+// TimeSource 时间来源，测试时换成假的时钟
+type TimeSource interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// LevelClock 比赛的级别计时器，按时间推进盲注表，可以暂停
+// 所有方法都可以在多个 goroutine 里调用，发牌的循环每一手开始前读 Blinds 就行
+type LevelClock struct {
+	mu       sync.Mutex
+	schedule *Schedule
+	clock    TimeSource
+
+	started bool
+	paused  bool
+	since   time.Time     // 最近一次开始或者继续的时间
+	elapsed time.Duration // 这之前已经走过的时间（不含暂停）
+}
+
+// NewLevelClock 创建计时器，clock 为 nil 时用系统时间，需要 Start 之后才开始走
+func NewLevelClock(schedule *Schedule, clock TimeSource) *LevelClock {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &LevelClock{
+		schedule: schedule,
+		clock:    clock,
+	}
+}
+
+// Start 开始计时，已经开始的话什么都不做
+func (c *LevelClock) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return
+	}
+	c.started = true
+	c.since = c.clock.Now()
+}
+
+// Pause 暂停计时
+func (c *LevelClock) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started || c.paused {
+		return
+	}
+	c.elapsed += c.clock.Now().Sub(c.since)
+	c.paused = true
+}
+
+// Resume 继续计时
+func (c *LevelClock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	c.since = c.clock.Now()
+}
+
+// Elapsed 比赛开始之后走过的时间，不含暂停
+func (c *LevelClock) Elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.elapsedLocked()
+}
+
+func (c *LevelClock) elapsedLocked() time.Duration {
+	if !c.started || c.paused {
+		return c.elapsed
+	}
+	return c.elapsed + c.clock.Now().Sub(c.since)
+}
+
+// locate 走过 elapsed 之后所在的级别，以及这个级别还剩多少时间（不限时的级别为 0）
+func (c *LevelClock) locate(elapsed time.Duration) (int, time.Duration) {
+	levels := c.schedule.Levels
+	for i := range levels {
+		d := time.Duration(levels[i].Duration)
+		if d == 0 {
+			return i, 0
+		}
+		if elapsed < d {
+			return i, d - elapsed
+		}
+		elapsed -= d
+	}
+	// 最后一个级别到时间之后一直停在最后一个级别
+	return len(levels) - 1, 0
+}
+
+// LevelIndex 当前级别在盲注表里的下标
+func (c *LevelClock) LevelIndex() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, _ := c.locate(c.elapsedLocked())
+	return i
+}
+
+// Level 当前的级别
+func (c *LevelClock) Level() Level {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, _ := c.locate(c.elapsedLocked())
+	return c.schedule.Levels[i]
+}
+
+// Remaining 当前级别还剩多少时间，不限时的级别为 0
+func (c *LevelClock) Remaining() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, remaining := c.locate(c.elapsedLocked())
+	return remaining
+}
+
+// OnBreak 现在是否在休息
+func (c *LevelClock) OnBreak() bool {
+	return c.Level().Break
+}
+
+// Blinds 现在要下的盲注和前注，休息时返回休息前最后一个级别的
+func (c *LevelClock) Blinds() Blinds {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, _ := c.locate(c.elapsedLocked())
+	for ; i > 0 && c.schedule.Levels[i].Break; i-- {
+	}
+	return c.schedule.Levels[i].Blinds()
+}
+
+// ColorUpUnit 休息时要换成的最小筹码面额，不在休息或者不换时为 0
+func (c *LevelClock) ColorUpUnit() int64 {
+	l := c.Level()
+	if !l.Break {
+		return 0
+	}
+	return l.ColorUp
+}
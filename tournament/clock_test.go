@@ -0,0 +1,71 @@
+package tournament
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestLevelClock(t *testing.T) {
+	s, err := ParseSchedule([]byte(testSchedule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeClock{now: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c := NewLevelClock(s, fake)
+
+	// 没开始之前停在第一个级别
+	fake.Advance(time.Hour)
+	if c.LevelIndex() != 0 || c.Remaining() != 20*time.Minute {
+		t.Error("err level before start", c.LevelIndex(), c.Remaining())
+	}
+
+	c.Start()
+	fake.Advance(19 * time.Minute)
+	if c.LevelIndex() != 0 || c.Remaining() != time.Minute || c.Blinds() != (Blinds{SmallBlind: 25, BigBlind: 50}) {
+		t.Error("err level 0", c.LevelIndex(), c.Remaining(), c.Blinds())
+	}
+	fake.Advance(time.Minute)
+	if c.LevelIndex() != 1 || c.Blinds() != (Blinds{SmallBlind: 50, BigBlind: 100, BigBlindAnte: 100}) {
+		t.Error("err level 1", c.LevelIndex(), c.Blinds())
+	}
+
+	// 暂停的时间不算
+	c.Pause()
+	fake.Advance(time.Hour)
+	if c.LevelIndex() != 1 || c.Elapsed() != 20*time.Minute {
+		t.Error("err paused", c.LevelIndex(), c.Elapsed())
+	}
+	c.Resume()
+
+	fake.Advance(25 * time.Minute)
+	if !c.OnBreak() || c.ColorUpUnit() != 100 || c.Remaining() != 5*time.Minute {
+		t.Error("err break", c.OnBreak(), c.ColorUpUnit(), c.Remaining())
+	}
+	// 休息时读到的还是休息前的盲注
+	if c.Blinds() != (Blinds{SmallBlind: 50, BigBlind: 100, BigBlindAnte: 100}) {
+		t.Error("err blinds on break", c.Blinds())
+	}
+
+	fake.Advance(5 * time.Minute)
+	if c.OnBreak() || c.ColorUpUnit() != 0 || c.Blinds() != (Blinds{SmallBlind: 100, BigBlind: 200, Ante: 25}) {
+		t.Error("err level 3", c.Level(), c.Blinds())
+	}
+
+	// 最后一个级别一直持续
+	fake.Advance(24 * time.Hour)
+	if c.LevelIndex() != 4 || c.Remaining() != 0 {
+		t.Error("err last level", c.LevelIndex(), c.Remaining())
+	}
+}
@@ -0,0 +1,296 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	texas_holdem "github.com/zack-wong/TexasDemo/texas_alg"
+)
+
+// Assignment 玩家坐在哪张桌子的哪个座位
+type Assignment struct {
+	TableID int32
+	SeatID  int32
+}
+
+// Move 一次换座位，From 为空（TableID 为 0）表示抽签入座
+type Move struct {
+	PlayerID int64
+	From     Assignment
+	To       Assignment
+}
+
+// MTTTable 多桌赛里的一张桌子
+type MTTTable struct {
+	ID       int32
+	Seats    map[int32]int64 // 座位号到玩家，座位号从 1 开始
+	BigBlind int32           // 下一手大盲的座位，平衡时从这个位置开始移人
+}
+
+// Players 桌上的玩家数
+func (t *MTTTable) Players() int {
+	return len(t.Seats)
+}
+
+func (t *MTTTable) seatIDs() []int32 {
+	seats := make([]int32, 0, len(t.Seats))
+	for seatID := range t.Seats {
+		seats = append(seats, seatID)
+	}
+	sort.Slice(seats, func(i, j int) bool { return seats[i] < seats[j] })
+	return seats
+}
+
+// Director 多桌赛的裁判：抽签入座、拆桌、平衡人数、合并成决赛桌
+// 所有随机都来自 seed，同样的 seed 和同样的调用顺序得到同样的结果
+type Director struct {
+	rand      *rand.Rand
+	tableSize int
+	tables    []*MTTTable // 按桌号排好
+	players   map[int64]Assignment
+	final     bool
+}
+
+// NewDirector 创建裁判，tableSize 是每张桌子最多坐几个人，也是决赛桌的人数
+func NewDirector(seed int64, tableSize int) *Director {
+	return &Director{
+		rand:      rand.New(rand.NewSource(seed)),
+		tableSize: tableSize,
+		players:   make(map[int64]Assignment),
+	}
+}
+
+// shuffle 和 poker.Dealer 一样的洗法
+func (d *Director) shuffle(n int, swap func(i, j int)) {
+	for i := 0; i < n; i++ {
+		j := d.rand.Intn(n-i) + i
+		swap(i, j)
+	}
+}
+
+// Draw 抽签入座：开 ceil(人数/每桌人数) 张桌子，打乱之后轮流分到各张桌子，每张桌子随机选座位
+func (d *Director) Draw(playerIDs []int64) ([]Move, error) {
+	if len(d.players) > 0 {
+		return nil, errors.New("seats already drawn")
+	}
+	if d.tableSize < 2 {
+		return nil, fmt.Errorf("invalid table size %d", d.tableSize)
+	}
+	if len(playerIDs) < 2 {
+		return nil, errors.New("not enough players")
+	}
+	seen := make(map[int64]bool, len(playerIDs))
+	for _, id := range playerIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate player %d", id)
+		}
+		seen[id] = true
+	}
+
+	players := append([]int64(nil), playerIDs...)
+	d.shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+
+	n := (len(players) + d.tableSize - 1) / d.tableSize
+	groups := make([][]int64, n)
+	for i, id := range players {
+		groups[i%n] = append(groups[i%n], id)
+	}
+	var moves []Move
+	for i, group := range groups {
+		t := &MTTTable{ID: int32(i + 1), Seats: make(map[int32]int64), BigBlind: texas_holdem.NO_SEAT}
+		d.tables = append(d.tables, t)
+		moves = append(moves, d.seatRandomly(t, group, nil)...)
+	}
+	d.final = n == 1
+	return moves, nil
+}
+
+// seatRandomly 把玩家随机安排到 t 的空座位上
+func (d *Director) seatRandomly(t *MTTTable, playerIDs []int64, from map[int64]Assignment) []Move {
+	var free []int32
+	for seatID := int32(1); seatID <= int32(d.tableSize); seatID++ {
+		if _, ok := t.Seats[seatID]; !ok {
+			free = append(free, seatID)
+		}
+	}
+	d.shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+
+	moves := make([]Move, 0, len(playerIDs))
+	for i, id := range playerIDs {
+		to := Assignment{TableID: t.ID, SeatID: free[i]}
+		t.Seats[to.SeatID] = id
+		d.players[id] = to
+		moves = append(moves, Move{PlayerID: id, From: from[id], To: to})
+	}
+	return moves
+}
+
+// Tables 现在所有的桌子，按桌号排好
+func (d *Director) Tables() []*MTTTable {
+	return d.tables
+}
+
+// Table 桌号对应的桌子，没有时返回 nil
+func (d *Director) Table(tableID int32) *MTTTable {
+	for _, t := range d.tables {
+		if t.ID == tableID {
+			return t
+		}
+	}
+	return nil
+}
+
+// Seat 玩家现在的位置
+func (d *Director) Seat(playerID int64) (Assignment, bool) {
+	a, ok := d.players[playerID]
+	return a, ok
+}
+
+// Remaining 还剩多少玩家
+func (d *Director) Remaining() int {
+	return len(d.players)
+}
+
+// FinalTable 是否已经合并成决赛桌
+func (d *Director) FinalTable() bool {
+	return d.final
+}
+
+// SetBigBlind 桌子报告下一手大盲的座位，平衡时从这个位置移人
+func (d *Director) SetBigBlind(tableID, seatID int32) error {
+	t := d.Table(tableID)
+	if t == nil {
+		return fmt.Errorf("table %d not found", tableID)
+	}
+	t.BigBlind = seatID
+	return nil
+}
+
+// Bust 玩家出局，之后拆桌、平衡人数，返回需要换座位的玩家
+// 有没坐下或者重复的玩家时返回错误，谁都不出局
+func (d *Director) Bust(playerIDs ...int64) ([]Move, error) {
+	seen := make(map[int64]bool, len(playerIDs))
+	for _, id := range playerIDs {
+		if _, ok := d.players[id]; !ok {
+			return nil, fmt.Errorf("player %d not seated", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate player %d", id)
+		}
+		seen[id] = true
+	}
+	for _, id := range playerIDs {
+		a := d.players[id]
+		delete(d.Table(a.TableID).Seats, a.SeatID)
+		delete(d.players, id)
+	}
+	return d.Rebalance(), nil
+}
+
+// Rebalance 拆桌并平衡人数：
+// 人数坐得下更少的桌子时，拆掉人最少的桌子（一样多时拆桌号大的），玩家随机坐到人最少的桌子的空座位；
+// 只剩一张桌子时所有人重新抽签坐决赛桌；
+// 各桌人数相差超过 1 时，从人最多的桌子把大盲位置的玩家移到人最少的桌子
+func (d *Director) Rebalance() []Move {
+	var moves []Move
+	if len(d.players) == 0 {
+		return moves
+	}
+	need := (len(d.players) + d.tableSize - 1) / d.tableSize
+	for len(d.tables) > need {
+		moves = append(moves, d.breakTable()...)
+	}
+	if len(d.tables) == 1 && !d.final {
+		return append(moves, d.mergeFinalTable()...)
+	}
+
+	for {
+		most, fewest := d.tables[0], d.tables[0]
+		for _, t := range d.tables {
+			if t.Players() > most.Players() {
+				most = t
+			}
+			if t.Players() < fewest.Players() {
+				fewest = t
+			}
+		}
+		if most.Players()-fewest.Players() <= 1 {
+			return moves
+		}
+		moves = append(moves, d.moveBigBlind(most, fewest))
+	}
+}
+
+// breakTable 拆掉人最少的桌子
+func (d *Director) breakTable() []Move {
+	idx := 0
+	for i, t := range d.tables {
+		if t.Players() <= d.tables[idx].Players() {
+			idx = i
+		}
+	}
+	broken := d.tables[idx]
+	d.tables = append(d.tables[:idx], d.tables[idx+1:]...)
+
+	var moves []Move
+	for _, seatID := range broken.seatIDs() {
+		id := broken.Seats[seatID]
+		to := d.tables[0]
+		for _, t := range d.tables {
+			if t.Players() < to.Players() {
+				to = t
+			}
+		}
+		from := map[int64]Assignment{id: d.players[id]}
+		moves = append(moves, d.seatRandomly(to, []int64{id}, from)...)
+	}
+	return moves
+}
+
+// mergeFinalTable 所有人重新抽签坐决赛桌
+func (d *Director) mergeFinalTable() []Move {
+	t := d.tables[0]
+	from := make(map[int64]Assignment, len(t.Seats))
+	players := make([]int64, 0, len(t.Seats))
+	for _, seatID := range t.seatIDs() {
+		id := t.Seats[seatID]
+		from[id] = d.players[id]
+		players = append(players, id)
+	}
+	d.shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+
+	t.Seats = make(map[int32]int64)
+	t.BigBlind = texas_holdem.NO_SEAT
+	d.final = true
+	return d.seatRandomly(t, players, from)
+}
+
+// moveBigBlind 把 from 桌大盲位置（没人就是之后第一个有人的座位）的玩家移到 to 桌
+func (d *Director) moveBigBlind(from, to *MTTTable) Move {
+	seats := from.seatIDs()
+	seatID := seats[0]
+	for _, s := range seats {
+		if s >= from.BigBlind {
+			seatID = s
+			break
+		}
+	}
+	id := from.Seats[seatID]
+	delete(from.Seats, seatID)
+
+	// 大盲移走之后，下一个人变成大盲
+	from.BigBlind = texas_holdem.NO_SEAT
+	for _, s := range from.seatIDs() {
+		if s > seatID {
+			from.BigBlind = s
+			break
+		}
+	}
+	if from.BigBlind == texas_holdem.NO_SEAT && from.Players() > 0 {
+		from.BigBlind = from.seatIDs()[0]
+	}
+
+	return d.seatRandomly(to, []int64{id}, map[int64]Assignment{id: d.players[id]})[0]
+}
@@ -0,0 +1,148 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+)
+
+func drawPlayers(t *testing.T, seed int64, n int) (*Director, []Move) {
+	t.Helper()
+	players := make([]int64, n)
+	for i := range players {
+		players[i] = int64(1000 + i)
+	}
+	d := NewDirector(seed, 9)
+	moves, err := d.Draw(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, moves
+}
+
+func checkTables(t *testing.T, d *Director) {
+	t.Helper()
+	seen := make(map[int64]bool)
+	min, max := 100, 0
+	for _, table := range d.Tables() {
+		for seatID, id := range table.Seats {
+			if seatID < 1 || seatID > 9 || seen[id] {
+				t.Fatal("err seat", table.ID, seatID, id)
+			}
+			seen[id] = true
+			if a, _ := d.Seat(id); a != (Assignment{TableID: table.ID, SeatID: seatID}) {
+				t.Fatal("err assignment", id, a)
+			}
+		}
+		if table.Players() < min {
+			min = table.Players()
+		}
+		if table.Players() > max {
+			max = table.Players()
+		}
+	}
+	if len(seen) != d.Remaining() || max-min > 1 {
+		t.Fatal("tables not balanced", min, max, len(seen), d.Remaining())
+	}
+}
+
+func TestDirectorDraw(t *testing.T) {
+	d, moves := drawPlayers(t, 42, 25)
+	if len(d.Tables()) != 3 || len(moves) != 25 || d.FinalTable() {
+		t.Fatal("err draw", len(d.Tables()), len(moves))
+	}
+	checkTables(t, d)
+
+	_, again := drawPlayers(t, 42, 25)
+	if !reflect.DeepEqual(moves, again) {
+		t.Error("same seed should draw the same seats")
+	}
+	_, other := drawPlayers(t, 43, 25)
+	if reflect.DeepEqual(moves, other) {
+		t.Error("different seed should draw different seats")
+	}
+	if _, err := d.Draw([]int64{1, 2}); err == nil {
+		t.Error("draw twice should fail")
+	}
+	if _, err := NewDirector(1, 9).Draw([]int64{1, 1}); err == nil {
+		t.Error("duplicate player should fail")
+	}
+}
+
+func TestDirectorBalanceFromBigBlind(t *testing.T) {
+	d, _ := drawPlayers(t, 7, 18)
+	t1, t2 := d.Table(1), d.Table(2)
+
+	// 1 号桌连着出局两个人，2 号桌大盲位置的玩家移过去
+	seats := t1.seatIDs()
+	if _, err := d.Bust(t1.Seats[seats[0]]); err != nil {
+		t.Fatal(err)
+	}
+	bbSeat := t2.seatIDs()[3]
+	bbPlayer := t2.Seats[bbSeat]
+	d.SetBigBlind(2, bbSeat)
+	moves, err := d.Bust(t1.Seats[seats[1]])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(moves) != 1 || moves[0].PlayerID != bbPlayer || moves[0].From != (Assignment{TableID: 2, SeatID: bbSeat}) || moves[0].To.TableID != 1 {
+		t.Fatal("err balance move", moves)
+	}
+	if t2.BigBlind <= bbSeat {
+		t.Error("big blind should move to the next player", t2.BigBlind)
+	}
+	checkTables(t, d)
+
+	if _, err := d.Bust(bbPlayer, bbPlayer); err == nil {
+		t.Error("bust twice should fail")
+	}
+}
+
+func TestDirectorBreakAndFinalTable(t *testing.T) {
+	replay := func() []Move {
+		d, _ := drawPlayers(t, 99, 30)
+		var all []Move
+		for id := int64(1000); d.Remaining() > 9; id++ {
+			moves, err := d.Bust(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, moves...)
+			checkTables(t, d)
+			if need := (d.Remaining() + 8) / 9; len(d.Tables()) != need {
+				t.Fatal("err table count", len(d.Tables()), need)
+			}
+		}
+		if !d.FinalTable() || len(d.Tables()) != 1 || d.Tables()[0].Players() != 9 {
+			t.Fatal("err final table", d.FinalTable(), len(d.Tables()))
+		}
+		return all
+	}
+	if !reflect.DeepEqual(replay(), replay()) {
+		t.Error("same seed should replay the same moves")
+	}
+}
+
+func TestDirectorBustInvalid(t *testing.T) {
+	d, _ := drawPlayers(t, 7, 25)
+	snapshot := func() map[int32]map[int32]int64 {
+		res := make(map[int32]map[int32]int64)
+		for _, table := range d.Tables() {
+			seats := make(map[int32]int64)
+			for seatID, id := range table.Seats {
+				seats[seatID] = id
+			}
+			res[table.ID] = seats
+		}
+		return res
+	}
+	before := snapshot()
+	for _, ids := range [][]int64{{1000, 1001, 99}, {1000, 1001, 1000}} {
+		if _, err := d.Bust(ids...); err == nil {
+			t.Fatal("expect error", ids)
+		}
+		if !reflect.DeepEqual(snapshot(), before) || d.Remaining() != 25 {
+			t.Fatal("failed bust should not change tables", ids)
+		}
+	}
+	checkTables(t, d)
+}
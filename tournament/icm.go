@@ -0,0 +1,265 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	texas_holdem "github.com/zack-wong/TexasDemo/texas_alg"
+)
+
+const (
+	// ICM_EXACT_PLAYERS 不超过这么多人时精确计算，人更多时用蒙特卡洛
+	ICM_EXACT_PLAYERS = 10
+	// ICM_EXACT_MAX_PLAYERS ICMExact 最多算多少人，再多内存不够
+	ICM_EXACT_MAX_PLAYERS = 20
+	// DEFAULT_ICM_SAMPLES 蒙特卡洛默认的模拟次数
+	DEFAULT_ICM_SAMPLES = 200000
+)
+
+/* 独立筹码模型（ICM）
+---------------------------------------------------------------
+每个名次都按剩下的人的筹码比例抽：第一名是 i 的概率是 i 的筹码 / 总筹码，
+第一名确定之后，第二名是 j 的概率是 j 的筹码 / 剩下的总筹码，依次类推。
+每个人的奖金期望 = Σ 拿到第 k 名的概率 × 第 k 名的奖金
+精确计算按已经排好名次的人的集合做状态压缩 DP，一共 2^n 个状态；
+蒙特卡洛每次给每个人抽一个 -ln(U)/筹码，从小到大排就是按上面的规则抽出来的名次
+*/
+
+// ICMEquity 按 ICM 把筹码换算成奖金期望
+// stacks 是每个座位的筹码，payouts 是第一名开始每个名次的奖金；筹码为 0 的座位已经出局，期望为 0
+// 不超过 ICM_EXACT_PLAYERS 人时精确计算，否则用默认次数的蒙特卡洛
+func ICMEquity(stacks texas_holdem.SeatID2WinAmount, payouts []int64) (map[int32]float64, error) {
+	seats, err := _icmSeats(stacks, payouts)
+	if err != nil {
+		return nil, err
+	}
+	if len(seats) <= ICM_EXACT_PLAYERS {
+		return _icmExact(stacks, seats, payouts), nil
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return _icmMonteCarlo(stacks, seats, payouts, DEFAULT_ICM_SAMPLES, r), nil
+}
+
+// ICMExact 精确计算 ICM，人数多的时候很慢（2^n）
+func ICMExact(stacks texas_holdem.SeatID2WinAmount, payouts []int64) (map[int32]float64, error) {
+	seats, err := _icmSeats(stacks, payouts)
+	if err != nil {
+		return nil, err
+	}
+	if len(seats) > ICM_EXACT_MAX_PLAYERS {
+		return nil, fmt.Errorf("too many players for exact ICM: %d", len(seats))
+	}
+	return _icmExact(stacks, seats, payouts), nil
+}
+
+// ICMMonteCarlo 模拟 samples 次名次估算 ICM，r 为 nil 时用当前时间做种子
+func ICMMonteCarlo(stacks texas_holdem.SeatID2WinAmount, payouts []int64, samples int, r *rand.Rand) (map[int32]float64, error) {
+	seats, err := _icmSeats(stacks, payouts)
+	if err != nil {
+		return nil, err
+	}
+	if samples <= 0 {
+		samples = DEFAULT_ICM_SAMPLES
+	}
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return _icmMonteCarlo(stacks, seats, payouts, samples, r), nil
+}
+
+// _icmSeats 检查输入，返回还有筹码的座位，按座位号排好
+func _icmSeats(stacks texas_holdem.SeatID2WinAmount, payouts []int64) ([]int32, error) {
+	for i, p := range payouts {
+		if p < 0 {
+			return nil, fmt.Errorf("negative payout %d for place %d", p, i+1)
+		}
+	}
+	var seats []int32
+	for seatID, stack := range stacks {
+		if stack < 0 {
+			return nil, fmt.Errorf("seat %d has negative stack %d", seatID, stack)
+		}
+		if stack > 0 {
+			seats = append(seats, seatID)
+		}
+	}
+	if len(seats) == 0 {
+		return nil, errors.New("no chips in play")
+	}
+	sort.Slice(seats, func(i, j int) bool { return seats[i] < seats[j] })
+	return seats, nil
+}
+
+func _icmResult(stacks texas_holdem.SeatID2WinAmount) map[int32]float64 {
+	res := make(map[int32]float64, len(stacks))
+	for seatID := range stacks {
+		res[seatID] = 0
+	}
+	return res
+}
+
+func _icmExact(stacks texas_holdem.SeatID2WinAmount, seats []int32, payouts []int64) map[int32]float64 {
+	n := len(seats)
+	places := n
+	if len(payouts) < places {
+		places = len(payouts)
+	}
+	total := 0.0
+	for _, seatID := range seats {
+		total += float64(stacks[seatID])
+	}
+
+	equity := make([]float64, n)
+	// prob[mask] 前 popcount(mask) 名正好是 mask 里这些人的概率，placed[mask] 是他们的筹码之和
+	prob := make([]float64, 1<<uint(n))
+	placed := make([]float64, 1<<uint(n))
+	prob[0] = 1
+	for mask := 0; mask < len(prob); mask++ {
+		p := prob[mask]
+		if p == 0 {
+			continue
+		}
+		place := _popCount(mask)
+		if place >= places {
+			continue
+		}
+		rest := total - placed[mask]
+		for j := 0; j < n; j++ {
+			bit := 1 << uint(j)
+			if mask&bit != 0 {
+				continue
+			}
+			stack := float64(stacks[seats[j]])
+			pj := p * stack / rest
+			equity[j] += pj * float64(payouts[place])
+			prob[mask|bit] += pj
+			placed[mask|bit] = placed[mask] + stack
+		}
+	}
+
+	res := _icmResult(stacks)
+	for i, seatID := range seats {
+		res[seatID] = equity[i]
+	}
+	return res
+}
+
+func _icmMonteCarlo(stacks texas_holdem.SeatID2WinAmount, seats []int32, payouts []int64, samples int, r *rand.Rand) map[int32]float64 {
+	n := len(seats)
+	places := n
+	if len(payouts) < places {
+		places = len(payouts)
+	}
+	equity := make([]float64, n)
+	keys := make([]float64, n)
+	order := make([]int, n)
+	for s := 0; s < samples; s++ {
+		for i, seatID := range seats {
+			keys[i] = -math.Log(1-r.Float64()) / float64(stacks[seatID])
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool { return keys[order[a]] < keys[order[b]] })
+		for place := 0; place < places; place++ {
+			equity[order[place]] += float64(payouts[place])
+		}
+	}
+
+	res := _icmResult(stacks)
+	for i, seatID := range seats {
+		res[seatID] = equity[i] / float64(samples)
+	}
+	return res
+}
+
+func _popCount(x int) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
+// ICMDeal 按 ICM 分奖金，结果是整数，加起来等于还没发的奖金
+func ICMDeal(stacks texas_holdem.SeatID2WinAmount, payouts []int64) (texas_holdem.SeatID2WinAmount, error) {
+	equity, err := ICMEquity(stacks, payouts)
+	if err != nil {
+		return nil, err
+	}
+	return RoundPayouts(equity, _prizeLeft(len(_nonZeroSeats(stacks)), payouts)), nil
+}
+
+// ChipChop 按筹码比例分奖金：每个人先拿剩下的人里最后一名的奖金，剩下的按筹码比例分
+func ChipChop(stacks texas_holdem.SeatID2WinAmount, payouts []int64) (texas_holdem.SeatID2WinAmount, error) {
+	seats, err := _icmSeats(stacks, payouts)
+	if err != nil {
+		return nil, err
+	}
+	n := len(seats)
+	guaranteed := int64(0)
+	if n <= len(payouts) {
+		guaranteed = payouts[n-1]
+	}
+	prize := _prizeLeft(n, payouts)
+	pool := float64(prize - guaranteed*int64(n))
+
+	total := int64(0)
+	for _, seatID := range seats {
+		total += stacks[seatID]
+	}
+	equity := _icmResult(stacks)
+	for _, seatID := range seats {
+		equity[seatID] = float64(guaranteed) + pool*float64(stacks[seatID])/float64(total)
+	}
+	return RoundPayouts(equity, prize), nil
+}
+
+// _prizeLeft 还剩 n 个人时没发的奖金，就是前 n 名的奖金之和
+func _prizeLeft(n int, payouts []int64) int64 {
+	sum := int64(0)
+	for i := 0; i < n && i < len(payouts); i++ {
+		sum += payouts[i]
+	}
+	return sum
+}
+
+func _nonZeroSeats(stacks texas_holdem.SeatID2WinAmount) []int32 {
+	var seats []int32
+	for seatID, stack := range stacks {
+		if stack > 0 {
+			seats = append(seats, seatID)
+		}
+	}
+	return seats
+}
+
+// RoundPayouts 把奖金期望取整，先都舍去小数，差的部分按小数从大到小每人补 1，小数一样时座位号小的优先
+// total 是要分的总额
+func RoundPayouts(equity map[int32]float64, total int64) texas_holdem.SeatID2WinAmount {
+	res := make(texas_holdem.SeatID2WinAmount, len(equity))
+	seats := make([]int32, 0, len(equity))
+	left := total
+	for seatID, v := range equity {
+		res[seatID] = int64(math.Floor(v))
+		left -= res[seatID]
+		seats = append(seats, seatID)
+	}
+	frac := func(seatID int32) float64 {
+		return equity[seatID] - math.Floor(equity[seatID])
+	}
+	sort.Slice(seats, func(i, j int) bool {
+		fi, fj := frac(seats[i]), frac(seats[j])
+		if fi != fj {
+			return fi > fj
+		}
+		return seats[i] < seats[j]
+	})
+	for i := 0; left > 0 && len(seats) > 0; i++ {
+		res[seats[i%len(seats)]]++
+		left--
+	}
+	return res
+}
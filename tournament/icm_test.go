@@ -0,0 +1,149 @@
+package tournament
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	texas_holdem "github.com/zack-wong/TexasDemo/texas_alg"
+)
+
+func sumPayout(m texas_holdem.SeatID2WinAmount) int64 {
+	sum := int64(0)
+	for _, v := range m {
+		sum += v
+	}
+	return sum
+}
+
+func TestICMExact(t *testing.T) {
+	stacks := texas_holdem.SeatID2WinAmount{1: 5000, 2: 3000, 3: 2000, 4: 0}
+	equity, err := ICMExact(stacks, []int64{50, 30, 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 号：50×0.5 + 30×(0.3×5/7 + 0.2×5/8) + 20×剩下的概率
+	second := 0.3*5/7 + 0.2*5/8
+	expect1 := 50*0.5 + 30*second + 20*(1-0.5-second)
+	if math.Abs(equity[1]-expect1) > 1e-9 || equity[4] != 0 {
+		t.Error("err equity", equity, expect1)
+	}
+	if sum := equity[1] + equity[2] + equity[3]; math.Abs(sum-100) > 1e-9 {
+		t.Error("err sum", sum)
+	}
+
+	// 筹码一样多时奖金期望一样
+	equity, _ = ICMExact(texas_holdem.SeatID2WinAmount{1: 100, 2: 100, 3: 100, 4: 100}, []int64{60, 40})
+	for seatID, v := range equity {
+		if math.Abs(v-25) > 1e-9 {
+			t.Error("err equal stacks", seatID, v)
+		}
+	}
+
+	if _, err := ICMExact(texas_holdem.SeatID2WinAmount{1: 0}, []int64{100}); err == nil {
+		t.Error("no chips should fail")
+	}
+	if _, err := ICMExact(texas_holdem.SeatID2WinAmount{1: -1, 2: 10}, []int64{100}); err == nil {
+		t.Error("negative stack should fail")
+	}
+}
+
+func TestICMMonteCarloAgreesWithExact(t *testing.T) {
+	stacks := texas_holdem.SeatID2WinAmount{}
+	for i := int32(1); i <= 9; i++ {
+		stacks[i] = int64(i) * 1000
+	}
+	payouts := Payouts(10000, 30, nil)
+	exact, err := ICMExact(stacks, payouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc, err := ICMMonteCarlo(stacks, payouts, 100000, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seatID := range stacks {
+		if math.Abs(exact[seatID]-mc[seatID]) > 15 {
+			t.Error("err monte carlo", seatID, exact[seatID], mc[seatID])
+		}
+	}
+
+	// 人多的时候 ICMEquity 用蒙特卡洛
+	for i := int32(10); i <= 12; i++ {
+		stacks[i] = 1000
+	}
+	equity, err := ICMEquity(stacks, payouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0.0
+	for _, v := range equity {
+		sum += v
+	}
+	if math.Abs(sum-10000) > 1e-6 || equity[9] <= equity[1] {
+		t.Error("err equity", equity)
+	}
+}
+
+func TestDeal(t *testing.T) {
+	stacks := texas_holdem.SeatID2WinAmount{1: 6000, 2: 3000, 3: 1000, 9: 0}
+	payouts := []int64{5000, 3000, 2000, 1000}
+
+	deal, err := ICMDeal(stacks, payouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sumPayout(deal) != 10000 || deal[9] != 0 || !(deal[1] > deal[2] && deal[2] > deal[3]) {
+		t.Error("err icm deal", deal)
+	}
+
+	// 每人先拿 2000，剩下 4000 按 6:3:1 分
+	chop, err := ChipChop(stacks, payouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chop[1] != 4400 || chop[2] != 3200 || chop[3] != 2400 || chop[9] != 0 {
+		t.Error("err chip chop", chop)
+	}
+}
+
+func TestRoundPayouts(t *testing.T) {
+	res := RoundPayouts(map[int32]float64{1: 33.4, 2: 33.3, 3: 33.3}, 100)
+	if res[1] != 34 || res[2] != 33 || res[3] != 33 {
+		t.Error("err round", res)
+	}
+}
+
+func TestPayouts(t *testing.T) {
+	cases := []struct {
+		entries int
+		expect  []int64
+	}{
+		{1, []int64{1001}},
+		{3, []int64{1001}},
+		{6, []int64{651, 350}},
+		{10, []int64{501, 300, 200}},
+	}
+	for _, c := range cases {
+		payouts := Payouts(1001, c.entries, nil)
+		if len(payouts) != len(c.expect) {
+			t.Error("err payouts", c.entries, payouts)
+			continue
+		}
+		for i := range payouts {
+			if payouts[i] != c.expect[i] {
+				t.Error("err payouts", c.entries, payouts)
+				break
+			}
+		}
+	}
+	for _, tpl := range DefaultPayoutTemplates {
+		sum := int64(0)
+		for _, rate := range tpl.Rates {
+			sum += rate
+		}
+		if sum != PAYOUT_RATE_BASE {
+			t.Error("err template", tpl)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tournament
+
+// PAYOUT_RATE_BASE 奖金比例的单位，万分比
+const PAYOUT_RATE_BASE = 10000
+
+// PayoutTemplate 奖金结构模板，报名人数不少于 MinEntries 时使用
+type PayoutTemplate struct {
+	MinEntries int
+	Rates      []int64 // 第一名开始每个名次的奖金比例，万分比，加起来是 PAYOUT_RATE_BASE
+}
+
+// DefaultPayoutTemplates 默认的奖金结构，按 MinEntries 从小到大排好
+var DefaultPayoutTemplates = []PayoutTemplate{
+	{MinEntries: 2, Rates: []int64{10000}},
+	{MinEntries: 4, Rates: []int64{6500, 3500}},
+	{MinEntries: 7, Rates: []int64{5000, 3000, 2000}},
+	{MinEntries: 11, Rates: []int64{4000, 2500, 1700, 1100, 700}},
+	{MinEntries: 21, Rates: []int64{3000, 2000, 1400, 1000, 800, 600, 500, 400, 300}},
+}
+
+// Payouts 按模板算出每个名次的奖金，templates 为 nil 时用 DefaultPayoutTemplates
+// 用 MinEntries 不超过 entries 的最后一个模板；不足 1 的部分都给第一名；人数不够任何模板时第一名拿全部
+func Payouts(prizePool int64, entries int, templates []PayoutTemplate) []int64 {
+	if templates == nil {
+		templates = DefaultPayoutTemplates
+	}
+	rates := []int64{PAYOUT_RATE_BASE}
+	for _, t := range templates {
+		if entries >= t.MinEntries {
+			rates = t.Rates
+		}
+	}
+
+	payouts := make([]int64, len(rates))
+	left := prizePool
+	for i, rate := range rates {
+		payouts[i] = prizePool * rate / PAYOUT_RATE_BASE
+		left -= payouts[i]
+	}
+	payouts[0] += left
+	return payouts
+}
@@ -0,0 +1,187 @@
+package tournament
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	texas_holdem "github.com/zack-wong/TexasDemo/texas_alg"
+)
+
+// Duration JSON 里可以写成 "20m" 这样的字符串，或者秒数
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(v)
+		return nil
+	}
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = Duration(time.Duration(seconds) * time.Second)
+	return nil
+}
+
+// Level 盲注表里的一个级别，Break 为 true 时是休息
+type Level struct {
+	SmallBlind   int64    `json:"small_blind,omitempty"`
+	BigBlind     int64    `json:"big_blind,omitempty"`
+	Ante         int64    `json:"ante,omitempty"`           // 每个人的前注
+	BigBlindAnte int64    `json:"big_blind_ante,omitempty"` // 大盲替所有人下的前注
+	Duration     Duration `json:"duration"`                 // 这个级别的时长，最后一个级别可以为 0，表示一直持续
+	Break        bool     `json:"break,omitempty"`          // 休息，不打牌
+	ColorUp      int64    `json:"color_up,omitempty"`       // 休息时去掉小面额的筹码，所有人的筹码凑成这个数的整数倍，0 表示不换
+}
+
+// Blinds 当前要下的盲注和前注
+type Blinds struct {
+	SmallBlind   int64
+	BigBlind     int64
+	Ante         int64
+	BigBlindAnte int64
+}
+
+func (l *Level) Blinds() Blinds {
+	return Blinds{
+		SmallBlind:   l.SmallBlind,
+		BigBlind:     l.BigBlind,
+		Ante:         l.Ante,
+		BigBlindAnte: l.BigBlindAnte,
+	}
+}
+
+// Apply 把盲注和前注填进桌子的配置，有大盲前注时用大盲前注，否则用每个人的前注
+func (b Blinds) Apply(config *texas_holdem.TableConfig) {
+	config.SmallBlind = b.SmallBlind
+	config.BigBlind = b.BigBlind
+	switch {
+	case b.BigBlindAnte > 0:
+		config.Ante = b.BigBlindAnte
+		config.AnteType = texas_holdem.AnteBigBlind
+	case b.Ante > 0:
+		config.Ante = b.Ante
+		config.AnteType = texas_holdem.AnteEveryone
+	default:
+		config.Ante = 0
+		config.AnteType = texas_holdem.AnteNone
+	}
+}
+
+// Schedule 比赛的盲注表
+type Schedule struct {
+	Name   string  `json:"name,omitempty"`
+	Levels []Level `json:"levels"`
+}
+
+// ParseSchedule 从 JSON 读盲注表，例如
+//
+//	{"levels": [
+//	  {"small_blind": 25, "big_blind": 50, "duration": "20m"},
+//	  {"small_blind": 50, "big_blind": 100, "big_blind_ante": 100, "duration": "20m"},
+//	  {"break": true, "duration": "10m", "color_up": 100}
+//	]}
+func ParseSchedule(data []byte) (*Schedule, error) {
+	s := &Schedule{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSchedule 从 r 读 JSON 格式的盲注表
+func LoadSchedule(r io.Reader) (*Schedule, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchedule(data)
+}
+
+// Validate 检查盲注表：至少有一个打牌的级别，盲注不能变小，只有最后一个级别可以不限时
+func (s *Schedule) Validate() error {
+	if len(s.Levels) == 0 {
+		return errors.New("empty schedule")
+	}
+	if s.Levels[0].Break {
+		return errors.New("schedule starts with a break")
+	}
+	prev := int64(0)
+	for i := range s.Levels {
+		l := &s.Levels[i]
+		if l.Duration < 0 || (l.Duration == 0 && i != len(s.Levels)-1) {
+			return fmt.Errorf("level %d: invalid duration %v", i, time.Duration(l.Duration))
+		}
+		if l.ColorUp < 0 {
+			return fmt.Errorf("level %d: invalid color up %d", i, l.ColorUp)
+		}
+		if l.Break {
+			continue
+		}
+		if l.SmallBlind < 0 || l.BigBlind <= 0 || l.SmallBlind > l.BigBlind || l.Ante < 0 || l.BigBlindAnte < 0 {
+			return fmt.Errorf("level %d: invalid blinds %d/%d", i, l.SmallBlind, l.BigBlind)
+		}
+		if l.BigBlind < prev {
+			return fmt.Errorf("level %d: big blind %d goes down", i, l.BigBlind)
+		}
+		prev = l.BigBlind
+	}
+	return nil
+}
+
+// ColorUp 去掉小面额筹码（chip race）：每个人不够 unit 的零头收上来，按总额换成 unit 面额的筹码，
+// 一人最多一个，零头多的先拿（代替发牌比大小），一样多时座位号小的先拿；只剩零头的人优先，不会因为换筹码出局
+// remainder 是换完之后少了的筹码（原来的总额减去新的总额），凑不够一个 unit 的部分放进 remainder；
+// 只剩零头的人太多、收上来的零头不够每人一个 unit 时也给他们一个，这时 remainder 是负数
+func ColorUp(stacks map[int32]int64, unit int64) (res map[int32]int64, remainder int64) {
+	res = make(map[int32]int64, len(stacks))
+	var racers []int32
+	pool := int64(0)
+	for seatID, stack := range stacks {
+		if unit <= 0 || stack <= 0 {
+			res[seatID] = stack
+			continue
+		}
+		res[seatID] = stack / unit * unit
+		if odd := stack % unit; odd > 0 {
+			pool += odd
+			racers = append(racers, seatID)
+		}
+	}
+	sort.Slice(racers, func(i, j int) bool {
+		a, b := racers[i], racers[j]
+		if (res[a] == 0) != (res[b] == 0) {
+			return res[a] == 0
+		}
+		if stacks[a]%unit != stacks[b]%unit {
+			return stacks[a]%unit > stacks[b]%unit
+		}
+		return a < b
+	})
+
+	remainder = pool
+	for _, seatID := range racers {
+		if remainder < unit && res[seatID] > 0 {
+			break
+		}
+		res[seatID] += unit
+		remainder -= unit
+	}
+	return res, remainder
+}
@@ -0,0 +1,99 @@
+package tournament
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	texas_holdem "github.com/zack-wong/TexasDemo/texas_alg"
+)
+
+const testSchedule = `{"name": "daily", "levels": [
+	{"small_blind": 25, "big_blind": 50, "duration": "20m"},
+	{"small_blind": 50, "big_blind": 100, "big_blind_ante": 100, "duration": 1200},
+	{"break": true, "duration": "10m", "color_up": 100},
+	{"small_blind": 100, "big_blind": 200, "ante": 25, "duration": "20m"},
+	{"small_blind": 200, "big_blind": 400, "big_blind_ante": 400, "duration": 0}
+]}`
+
+func TestParseSchedule(t *testing.T) {
+	s, err := LoadSchedule(strings.NewReader(testSchedule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Levels) != 5 || time.Duration(s.Levels[1].Duration) != 20*time.Minute || !s.Levels[2].Break {
+		t.Error("err schedule", s)
+	}
+
+	bad := []string{
+		`{"levels": []}`,
+		`{"levels": [{"break": true, "duration": "10m"}]}`,
+		`{"levels": [{"small_blind": 50, "big_blind": 25, "duration": "10m"}]}`,
+		`{"levels": [{"small_blind": 25, "big_blind": 50}, {"small_blind": 50, "big_blind": 100}]}`,
+		`{"levels": [{"small_blind": 50, "big_blind": 100, "duration": "10m"}, {"small_blind": 25, "big_blind": 50}]}`,
+		`{"levels": [{"small_blind": 25, "big_blind": 50, "duration": "forever"}]}`,
+	}
+	for _, data := range bad {
+		if _, err := ParseSchedule([]byte(data)); err == nil {
+			t.Error("should fail", data)
+		}
+	}
+}
+
+func TestColorUp(t *testing.T) {
+	// 零头一共 25+50+75+25 = 175，换成一个 100，4 号只剩零头先拿，剩下 75 换不了
+	stacks := map[int32]int64{1: 1025, 2: 1050, 3: 1075, 4: 25, 5: 0}
+	res, remainder := ColorUp(stacks, 100)
+	expect := map[int32]int64{1: 1000, 2: 1000, 3: 1000, 4: 100, 5: 0}
+	if !reflect.DeepEqual(res, expect) || remainder != 75 {
+		t.Error("err color up", res, remainder)
+	}
+
+	// 零头多的先拿，一人最多一个
+	res, remainder = ColorUp(map[int32]int64{1: 1060, 2: 1070, 3: 1080, 4: 1090}, 100)
+	expect = map[int32]int64{1: 1000, 2: 1100, 3: 1100, 4: 1100}
+	if !reflect.DeepEqual(res, expect) || remainder != 0 {
+		t.Error("err color up", res, remainder)
+	}
+}
+
+func TestColorUpConserveChips(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		stacks := make(map[int32]int64)
+		total := int64(0)
+		for i := int32(1); i <= int32(r.Intn(9)+2); i++ {
+			stacks[i] = r.Int63n(5000)
+			total += stacks[i]
+		}
+		unit := []int64{5, 25, 100, 500}[r.Intn(4)]
+		res, remainder := ColorUp(stacks, unit)
+		after := int64(0)
+		for seatID, stack := range res {
+			after += stack
+			if stack%unit != 0 || (stacks[seatID] > 0 && stack == 0) {
+				t.Fatal("err stack", stacks[seatID], stack, unit)
+			}
+		}
+		if after+remainder != total {
+			t.Fatal("chips not conserved", total, after, remainder)
+		}
+		if remainder >= unit {
+			t.Fatal("remainder should be less than a unit", remainder, unit)
+		}
+	}
+}
+
+func TestBlindsApply(t *testing.T) {
+	config := texas_holdem.TableConfig{Ante: 5, AnteType: texas_holdem.AnteEveryone}
+	Blinds{SmallBlind: 50, BigBlind: 100, BigBlindAnte: 100}.Apply(&config)
+	if config.SmallBlind != 50 || config.BigBlind != 100 || config.Ante != 100 || config.AnteType != texas_holdem.AnteBigBlind {
+		t.Error("err config", config)
+	}
+	Blinds{SmallBlind: 25, BigBlind: 50}.Apply(&config)
+	if config.Ante != 0 || config.AnteType != texas_holdem.AnteNone {
+		t.Error("err config", config)
+	}
+}